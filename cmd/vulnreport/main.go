@@ -8,6 +8,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
@@ -44,18 +45,31 @@ import (
 )
 
 var (
-	localRepoPath = flag.String("local-cve-repo", "", "path to local repo, instead of cloning remote")
-	issueRepo     = flag.String("issue-repo", "github.com/golang/vulndb", "repo to create issues in")
-	githubToken   = flag.String("ghtoken", "", "GitHub access token (default: value of VULN_GITHUB_ACCESS_TOKEN)")
-	skipSymbols   = flag.Bool("skip-symbols", false, "for lint and fix, don't load package for symbols checks")
-	skipAlias     = flag.Bool("skip-alias", false, "for fix, skip adding new GHSAs and CVEs")
-	graphQL       = flag.Bool("graphql", false, "for create, fetch GHSAs from the Github GraphQL API instead of the OSV database")
-	preferCVE     = flag.Bool("cve", false, "for create, prefer CVEs over GHSAs as canonical source")
-	updateIssue   = flag.Bool("up", false, "for commit, create a CL that updates (doesn't fix) the tracking bug")
-	closedOk      = flag.Bool("closed-ok", false, "for create & create-excluded, allow closed issues to be created")
-	cpuprofile    = flag.String("cpuprofile", "", "write cpuprofile to file")
-	quiet         = flag.Bool("q", false, "quiet mode (suppress info logs)")
-	force         = flag.Bool("f", false, "for fix, force Fix to run even if there are no lint errors")
+	localRepoPath  = flag.String("local-cve-repo", "", "path to local repo, instead of cloning remote")
+	issueRepo      = flag.String("issue-repo", "github.com/golang/vulndb", "repo to create issues in")
+	githubToken    = flag.String("ghtoken", "", "GitHub access token (default: value of VULN_GITHUB_ACCESS_TOKEN)")
+	skipSymbols    = flag.Bool("skip-symbols", false, "for lint and fix, don't load package for symbols checks")
+	symbolsCache   = flag.String("symbols-cache", "", "for fix and symbols, directory in which to cache derived symbols (default: no caching)")
+	refreshSymbols = flag.Bool("refresh-symbols", false, "for fix, ignore previously cached derived symbols")
+	removeStale    = flag.Bool("remove-stale", false, "for symbols, remove derived symbols that are no longer derived")
+	publicPkgs     = flag.Bool("public", false, "for symbols, print the public packages and functions through which the symbols of internal packages are reached, instead of updating the report (loads all the module's packages)")
+	symbolsJobs    = flag.Int("j", 1, "for symbols, number of reports to update concurrently (with a summary of the errors at the end)")
+	dynamicCalls   = flag.Bool("assume-dynamic-calls", false, "for fix and symbols, consider all exported functions vulnerable in packages that call reflect.Value.Call or use //go:linkname")
+	debugSSA       = flag.Bool("debug-ssa", false, "for fix and symbols, write the SSA of the packages that symbols are derived from to stderr (for debugging)")
+	revision       = flag.String("revision", "", "for symbols, derive symbols from this pseudo-version or commit hash of the module instead of vulnerable_at (for triaging unreleased code)")
+	localDir       = flag.String("local-dir", "", "for symbols, derive symbols from the local checkout of the module in this directory instead of vulnerable_at (for triaging unreleased code)")
+	checkPackages  = flag.Bool("check-packages", false, "for lint, check that packages exist at the vulnerable_at version (downloads module zips)")
+	checkRepos     = flag.Bool("check-repos", false, "for lint, warn about references to GitHub repos that no longer exist")
+	minimalImports = flag.Bool("minimal-imports", false, "for osv, print the entry with packages omitted whose vulnerable symbols all call those of other packages, instead of writing it (loads packages)")
+	diffPublished  = flag.Bool("diff-published", false, "for osv, show how the entry differs from the published one (fetches it from vuln.go.dev)")
+	skipAlias      = flag.Bool("skip-alias", false, "for fix, skip adding new GHSAs and CVEs")
+	graphQL        = flag.Bool("graphql", false, "for create, fetch GHSAs from the Github GraphQL API instead of the OSV database")
+	preferCVE      = flag.Bool("cve", false, "for create, prefer CVEs over GHSAs as canonical source")
+	updateIssue    = flag.Bool("up", false, "for commit, create a CL that updates (doesn't fix) the tracking bug")
+	closedOk       = flag.Bool("closed-ok", false, "for create & create-excluded, allow closed issues to be created")
+	cpuprofile     = flag.String("cpuprofile", "", "write cpuprofile to file")
+	quiet          = flag.Bool("q", false, "quiet mode (suppress info logs)")
+	force          = flag.Bool("f", false, "for fix, force Fix to run even if there are no lint errors")
 )
 
 var (
@@ -82,7 +96,9 @@ func main() {
 		fmt.Fprintf(flag.CommandLine.Output(), "  cve filename.yaml ...: creates and saves CVE 5.0 record from the provided YAML reports\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "  fix filename.yaml ...: fixes and reformats YAML reports\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "  osv filename.yaml ...: converts YAML reports to OSV JSON and writes to data/osv\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  migrate filename.yaml ...: rewrites YAML reports that use a deprecated schema version in the current format\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "  set-dates filename.yaml ...: sets PublishDate of YAML reports\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  symbols filename.yaml ...: updates the derived symbols of YAML reports\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "  suggest filename.yaml ...: (EXPERIMENTAL) use AI to suggest summary and description for YAML reports\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "  commit filename.yaml ...: creates new commits for YAML reports\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "  xref filename.yaml ...: prints cross references for YAML reports\n")
@@ -150,6 +166,13 @@ func main() {
 		return
 	}
 
+	if cmd == "symbols" && *symbolsJobs > 1 {
+		if err := symbolsBatch(args); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	ghsaClient := ghsa.NewClient(ctx, *githubToken)
 	pc := proxy.NewDefaultClient()
 	var cmdFunc func(context.Context, string) error
@@ -166,6 +189,19 @@ func main() {
 		cmdFunc = func(ctx context.Context, name string) error { return fix(ctx, name, ghsaClient, pc, *force) }
 	case "osv":
 		cmdFunc = func(ctx context.Context, name string) error { return osvCmd(ctx, name, pc) }
+	case "symbols":
+		cmdFunc = func(ctx context.Context, name string) error { return symbolsCmd(ctx, name) }
+	case "migrate":
+		cmdFunc = func(_ context.Context, name string) error {
+			migrated, err := report.MigrateReport(name)
+			if err != nil {
+				return err
+			}
+			if migrated {
+				outlog.Println(name)
+			}
+			return nil
+		}
 	case "set-dates":
 		repo, err := gitrepo.Open(ctx, ".")
 		if err != nil {
@@ -745,8 +781,42 @@ func lint(_ context.Context, filename string, pc *proxy.Client) (err error) {
 	defer derrors.Wrap(&err, "lint(%q)", filename)
 	infolog.Printf("lint %s\n", filename)
 
-	_, err = report.ReadAndLint(filename, pc)
-	return err
+	r, err := report.ReadAndLint(filename, pc)
+	if err != nil {
+		return err
+	}
+	if *checkPackages {
+		// ReadAndLint has already performed the default checks,
+		// so any errors are from the package checks.
+		var lints []string
+		for _, iss := range r.LintIssues(pc, &report.LintConfig{CheckPackages: true}) {
+			if iss.Severity == report.SeverityError {
+				lints = append(lints, iss.Msg)
+			}
+		}
+		if len(lints) > 0 {
+			return fmt.Errorf("%v: contains lint warnings:\n%s", filename, strings.Join(lints, "\n"))
+		}
+	}
+	var cfg *report.LintConfig
+	if *checkRepos {
+		cfg = &report.LintConfig{URLCache: refURLCache}
+	}
+	logLintWarnings(r, pc, cfg)
+	return nil
+}
+
+// refURLCache is shared by the reports linted with -check-repos,
+// so that each repo is requested at most once.
+var refURLCache = report.NewURLCache(nil)
+
+// logLintWarnings logs the lint warnings for r, if any.
+func logLintWarnings(r *report.Report, pc *proxy.Client, cfg *report.LintConfig) {
+	for _, iss := range r.LintIssues(pc, cfg) {
+		if iss.Severity == report.SeverityWarning {
+			warnlog.Printf("%s: %s", r.ID, iss.Msg)
+		}
+	}
 }
 
 func fix(ctx context.Context, filename string, ghsaClient *ghsa.Client, pc *proxy.Client, force bool) (err error) {
@@ -757,6 +827,9 @@ func fix(ctx context.Context, filename string, ghsaClient *ghsa.Client, pc *prox
 	if err != nil {
 		return err
 	}
+	if r.ID == "" {
+		r.ID = report.GoID(filename)
+	}
 	if err := r.CheckFilename(filename); err != nil {
 		return err
 	}
@@ -834,7 +907,7 @@ func checkReportSymbols(r *report.Report) error {
 				infolog.Printf("%s: skipping symbol checks for package %s (reason: %q)\n", r.ID, p.Package, p.SkipFix)
 				continue
 			}
-			syms, err := symbols.Exported(m, p, errlog)
+			syms, err := exportedSymbols(m, p)
 			if err != nil {
 				return fmt.Errorf("package %s: %w", p.Package, err)
 			}
@@ -848,6 +921,160 @@ func checkReportSymbols(r *report.Report) error {
 	return nil
 }
 
+// symbolsOptions returns the options for deriving symbols given by the
+// -symbols-cache, -refresh-symbols, -assume-dynamic-calls, -debug-ssa,
+// -revision and -local-dir flags.
+func symbolsOptions() (*symbols.Options, error) {
+	opts := &symbols.Options{
+		Refresh:            *refreshSymbols,
+		AssumeDynamicCalls: *dynamicCalls,
+		Revision:           *revision,
+	}
+	if *localDir != "" {
+		dir, err := filepath.Abs(*localDir)
+		if err != nil {
+			return nil, err
+		}
+		opts.LocalDir = dir
+	}
+	if *debugSSA {
+		opts.DebugSSA = os.Stderr
+	}
+	if *symbolsCache != "" {
+		c, err := symbols.NewCache(*symbolsCache)
+		if err != nil {
+			return nil, err
+		}
+		opts.Cache = c
+	}
+	return opts, nil
+}
+
+// exportedSymbols returns the names of the symbols derived for package p
+// of module m, using the cache given by the -symbols-cache flag, if any.
+// It warns about listed symbols that no exported function can reach.
+func exportedSymbols(m *report.Module, p *report.Package) ([]string, error) {
+	opts, err := symbolsOptions()
+	if err != nil {
+		return nil, err
+	}
+	res, err := symbols.ExportedResult(m, p, opts, errlog)
+	if err != nil {
+		return nil, err
+	}
+	if res.Skip == symbols.VersionNotAffected {
+		return nil, fmt.Errorf("version %s of module %s is not affected", m.VulnerableAt, m.Module)
+	}
+	if res.BuildGoVersion != "" {
+		infolog.Printf("package %s: symbols derived with %s\n", p.Package, res.BuildGoVersion)
+	}
+	if len(res.UnreachableVulnSymbols) > 0 {
+		warnlog.Printf("package %s: symbols %s are not reachable from any exported function; consider removing them or adding a skip_fix reason\n",
+			p.Package, strings.Join(res.UnreachableVulnSymbols, ", "))
+	}
+	var names []string
+	for _, s := range res.Symbols {
+		names = append(names, s.Name)
+		if pos, ok := res.Positions[s.Name]; ok {
+			infolog.Printf("package %s: %s declared at %s:%d\n", p.Package, s.Name, pos.Filename, pos.Line)
+		}
+	}
+	return names, nil
+}
+
+// symbolsCmd updates the derived symbols of the report in filename,
+// and writes it back if they changed.
+func symbolsCmd(_ context.Context, filename string) (err error) {
+	defer derrors.Wrap(&err, "symbols(%q)", filename)
+
+	r, err := report.Read(filename)
+	if err != nil {
+		return err
+	}
+	opts, err := symbolsOptions()
+	if err != nil {
+		return err
+	}
+	if *publicPkgs {
+		return printPublicPackages(r, opts)
+	}
+	changed, err := symbols.UpdateSymbols(r, &symbols.UpdateOptions{
+		Options:     *opts,
+		RemoveStale: *removeStale,
+	}, errlog)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		infolog.Printf("%s: derived symbols are up to date\n", r.ID)
+		return nil
+	}
+	infolog.Printf("%s: updated derived symbols\n", r.ID)
+	return r.Write(filename)
+}
+
+// printPublicPackages prints the public entry points found by
+// symbols.PublicPackages for each internal package of r with symbols.
+func printPublicPackages(r *report.Report, opts *symbols.Options) error {
+	for _, m := range r.Modules {
+		for _, p := range m.Packages {
+			if len(p.Symbols) == 0 || !strings.Contains("/"+p.Package+"/", "/internal/") {
+				continue
+			}
+			public, err := symbols.PublicPackages(m, p, opts, errlog)
+			if err != nil {
+				return err
+			}
+			if len(public) == 0 {
+				warnlog.Printf("%s: no public package reaches the symbols of %s\n", r.ID, p.Package)
+				continue
+			}
+			paths := maps.Keys(public)
+			slices.Sort(paths)
+			outlog.Printf("%s: %s is reached through:\n", r.ID, p.Package)
+			for _, path := range paths {
+				outlog.Printf("  %s: %s\n", path, strings.Join(public[path], ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// symbolsBatch is like symbolsCmd, but updates the reports given
+// by args concurrently, as set by the -j flag.
+func symbolsBatch(args []string) error {
+	opts, err := symbolsOptions()
+	if err != nil {
+		return err
+	}
+	reports := make(map[string]*report.Report)
+	for _, arg := range args {
+		filename, err := argToFilename(arg)
+		if err != nil {
+			errlog.Println(err)
+			continue
+		}
+		r, err := report.Read(filename)
+		if err != nil {
+			errlog.Println(err)
+			continue
+		}
+		reports[filename] = r
+	}
+	changed, err := symbols.RegenerateAllSymbols(reports, *symbolsJobs, &symbols.UpdateOptions{
+		Options:     *opts,
+		RemoveStale: *removeStale,
+	}, infolog)
+	for _, filename := range changed {
+		if werr := reports[filename].Write(filename); werr != nil {
+			errlog.Println(werr)
+			continue
+		}
+		outlog.Println(filename)
+	}
+	return err
+}
+
 func osvCmd(_ context.Context, filename string, pc *proxy.Client) (err error) {
 	defer derrors.Wrap(&err, "osv(%q)", filename)
 
@@ -856,11 +1083,69 @@ func osvCmd(_ context.Context, filename string, pc *proxy.Client) (err error) {
 		return err
 	}
 	if !r.IsExcluded() {
+		if *minimalImports {
+			return printMinimalOSV(r)
+		}
 		if err := writeOSV(r); err != nil {
 			return err
 		}
 		outlog.Println(r.OSVFilename())
+		if *diffPublished {
+			return logPublishedDiff(r)
+		}
+	}
+	return nil
+}
+
+// logPublishedDiff logs the changes between the published OSV entry
+// for r and the one generated from it.
+func logPublishedDiff(r *report.Report) error {
+	diffs, err := report.DiffPublishedOSV(r)
+	switch {
+	case errors.Is(err, report.ErrNotPublished):
+		infolog.Printf("%s: not yet published\n", r.ID)
+		return nil
+	case err != nil:
+		return err
+	case len(diffs) == 0:
+		infolog.Printf("%s: no changes to the published entry\n", r.ID)
+	}
+	for _, d := range diffs {
+		outlog.Printf("%s: %s\n", r.ID, d)
+	}
+	return nil
+}
+
+// printMinimalOSV prints the OSV entry for r, without the packages
+// that symbols.RedundantPackages finds to be redundant. The entry is
+// not written to the database, which must be generated from the report
+// as is.
+func printMinimalOSV(r *report.Report) error {
+	opts, err := symbolsOptions()
+	if err != nil {
+		return err
+	}
+	r = r.Clone()
+	for _, m := range r.Modules {
+		if m.VulnerableAt == "" && !m.IsFirstParty() {
+			continue // can't be loaded
+		}
+		redundant, err := symbols.RedundantPackages(m, opts, errlog)
+		if err != nil {
+			return err
+		}
+		for _, p := range redundant {
+			infolog.Printf("%s: omitting redundant package %s\n", r.ID, p)
+		}
+		m.Packages = slices.DeleteFunc(m.Packages, func(p *report.Package) bool {
+			return slices.Contains(redundant, p.Package)
+		})
+	}
+	b, err := json.MarshalIndent(r.ToOSV(time.Time{}), "", "  ")
+	if err != nil {
+		return err
 	}
+	outlog.Println(string(b))
 	return nil
 }
 
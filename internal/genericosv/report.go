@@ -14,8 +14,6 @@ import (
 	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
 	"golang.org/x/mod/module"
-	"golang.org/x/vulndb/internal/cveschema5"
-	"golang.org/x/vulndb/internal/ghsa"
 	"golang.org/x/vulndb/internal/osv"
 	"golang.org/x/vulndb/internal/proxy"
 	"golang.org/x/vulndb/internal/report"
@@ -30,12 +28,7 @@ func (osv *Entry) ToReport(goID string, pc *proxy.Client) *report.Report {
 		Description: osv.Details,
 	}
 	addAlias := func(alias string) {
-		switch {
-		case cveschema5.IsCVE(alias):
-			r.CVEs = append(r.CVEs, alias)
-		case ghsa.IsGHSA(alias):
-			r.GHSAs = append(r.GHSAs, alias)
-		default:
+		if err := r.AddAlias(alias); err != nil {
 			r.Notes = append(r.Notes, &report.Note{
 				Body: fmt.Sprintf("found alias %s that is not a GHSA or CVE", alias),
 				Type: report.NoteTypeCreate,
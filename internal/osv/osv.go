@@ -245,4 +245,21 @@ type DatabaseSpecific struct {
 	// The URL of the Go advisory for this vulnerability, of the form
 	// "https://pkg.go.dev/GO-YYYY-XXXX".
 	URL string `json:"url,omitempty"`
+	// The review status of this report, if known.
+	ReviewStatus ReviewStatus `json:"review_status,omitempty"`
 }
+
+// ReviewStatus is the review status of a Go vulnerability report.
+type ReviewStatus string
+
+const (
+	// ReviewStatusReviewed indicates that a report has been fully
+	// reviewed by the Go security team.
+	ReviewStatusReviewed ReviewStatus = "REVIEWED"
+	// ReviewStatusUnreviewed indicates that a report was created
+	// automatically, and has not been fully reviewed.
+	ReviewStatusUnreviewed ReviewStatus = "UNREVIEWED"
+)
+
+// ReviewStatuses are the valid review statuses.
+var ReviewStatuses = []ReviewStatus{ReviewStatusReviewed, ReviewStatusUnreviewed}
@@ -86,37 +86,79 @@ func validate(e *osv.Entry, checkTimestamps bool) (err error) {
 			return fmt.Errorf("%w (published=%s, modified=%s)", errPublishedAfterModified, e.Published, e.Modified)
 		}
 	}
+	if errs := ValidateFields(e); len(errs) > 0 {
+		return errs[0].Err
+	}
+	return nil
+}
+
+// A FieldError is a problem with a field of an OSV entry.
+type FieldError struct {
+	// Field is the JSON name of the top-level field of the entry,
+	// with an index for elements of affected, e.g. "summary"
+	// or "affected[1]".
+	Field string
+	Err   error
+}
+
+func (fe *FieldError) Error() string {
+	return fmt.Sprintf("%s: %v", fe.Field, fe.Err)
+}
+
+func (fe *FieldError) Unwrap() error {
+	return fe.Err
+}
+
+// ValidateFields returns all the problems with the OSV entry, except for
+// its timestamps, in the order they are checked by Validate.
+// At most one problem is returned for each field.
+func ValidateFields(e *osv.Entry) []*FieldError {
+	var errs []*FieldError
+	add := func(field string, err error) {
+		errs = append(errs, &FieldError{Field: field, Err: err})
+	}
 
 	// Check for missing required fields.
-	switch {
-	case e.ID == "":
-		return errNoID
-	case e.SchemaVersion == "":
-		return errNoSchemaVersion
-	case e.Summary == "":
-		return errNoSummary
-	case e.Details == "" && !hasAdvisory(e):
-		return errNoDetails
-	case len(e.Affected) == 0:
-		return errNoAffected
-	case len(e.References) == 0:
-		return errNoReferences
-	case e.DatabaseSpecific == nil:
-		return errNoDatabaseSpecific
+	if e.ID == "" {
+		add("id", errNoID)
+	}
+	if e.SchemaVersion == "" {
+		add("schema_version", errNoSchemaVersion)
+	}
+	if e.Summary == "" {
+		add("summary", errNoSummary)
+	}
+	if e.Details == "" && !hasAdvisory(e) {
+		add("details", errNoDetails)
+	}
+	if len(e.Affected) == 0 {
+		add("affected", errNoAffected)
+	}
+	if len(e.References) == 0 {
+		add("references", errNoReferences)
+	}
+	if e.DatabaseSpecific == nil {
+		add("database_specific", errNoDatabaseSpecific)
 	}
 
-	for _, a := range e.Affected {
+	for i, a := range e.Affected {
 		if err := validateAffected(&a); err != nil {
-			return err
+			add(fmt.Sprintf("affected[%d]", i), err)
 		}
 	}
 	for _, alias := range e.Aliases {
 		if !ghsa.IsGHSA(alias) && !cveschema5.IsCVE(alias) {
-			return fmt.Errorf("%w (found alias %s)", errInvalidAlias, alias)
+			add("aliases", fmt.Errorf("%w (found alias %s)", errInvalidAlias, alias))
+			break
+		}
+	}
+	if e.DatabaseSpecific != nil {
+		if err := validateDatabaseSpecific(e.DatabaseSpecific); err != nil {
+			add("database_specific", err)
 		}
 	}
 
-	return validateDatabaseSpecific(e.DatabaseSpecific)
+	return errs
 }
 
 func hasAdvisory(entry *osv.Entry) bool {
@@ -327,3 +327,34 @@ func TestValidate(t *testing.T) {
 		}
 	})
 }
+
+func TestValidateFields(t *testing.T) {
+	if errs := ValidateFields(testEntry(nil)); len(errs) != 0 {
+		t.Errorf("ValidateFields() = %v, want no errors", errs)
+	}
+
+	e := testEntry(func(e *osv.Entry) {
+		e.Summary = ""
+		e.Affected[1].Ranges = nil
+		e.Aliases = []string{"CVE-1999-1111", "BAD-1", "BAD-2"}
+		e.Published = osv.Time{}
+	})
+	type fieldError struct {
+		field string
+		err   error
+	}
+	want := []fieldError{
+		{"summary", errNoSummary},
+		{"affected[1]", errNoRanges},
+		{"aliases", errInvalidAlias},
+	}
+	errs := ValidateFields(e)
+	if len(errs) != len(want) {
+		t.Fatalf("ValidateFields() = %v, want %d errors", errs, len(want))
+	}
+	for i, w := range want {
+		if errs[i].Field != w.field || !errors.Is(errs[i], w.err) {
+			t.Errorf("ValidateFields()[%d] = %v, want %s: %v", i, errs[i], w.field, w.err)
+		}
+	}
+}
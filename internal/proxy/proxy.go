@@ -7,6 +7,8 @@
 package proxy
 
 import (
+	"archive/zip"
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -58,6 +60,23 @@ func NewDefaultClient() *Client {
 	return NewClient(http.DefaultClient, proxyURL)
 }
 
+// ErrUnavailable is wrapped by the errors returned by Client methods
+// when the proxy could not be reached, or failed to serve a request
+// because of a server error, rate limiting or a timeout, rather than
+// because of the request (such as for a module or version that doesn't
+// exist). Such errors may be transient.
+var ErrUnavailable = errors.New("proxy unavailable")
+
+// unavailableError is an error that wraps ErrUnavailable,
+// without changing the message of the underlying error.
+type unavailableError struct {
+	err error
+}
+
+func (e *unavailableError) Error() string        { return e.err.Error() }
+func (e *unavailableError) Unwrap() error        { return e.err }
+func (e *unavailableError) Is(target error) bool { return target == ErrUnavailable }
+
 func (c *Client) lookup(urlSuffix string) ([]byte, error) {
 	url := fmt.Sprintf("%s/%s", c.url, urlSuffix)
 	if b, found := c.cache.get(urlSuffix); found {
@@ -69,12 +88,16 @@ func (c *Client) lookup(urlSuffix string) ([]byte, error) {
 	}
 	resp, err := c.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, &unavailableError{err}
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != 200 {
 		c.errLog.set(urlSuffix, resp.StatusCode)
-		return nil, fmt.Errorf("HTTP GET /%s returned status %v", urlSuffix, resp.Status)
+		err := fmt.Errorf("HTTP GET /%s returned status %v", urlSuffix, resp.Status)
+		if isUnavailableStatus(resp.StatusCode) {
+			return nil, &unavailableError{err}
+		}
+		return nil, err
 	}
 	b, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -84,6 +107,12 @@ func (c *Client) lookup(urlSuffix string) ([]byte, error) {
 	return b, nil
 }
 
+// isUnavailableStatus reports whether the HTTP status s means that the
+// proxy couldn't serve the request, rather than that the request was bad.
+func isUnavailableStatus(s int) bool {
+	return s >= 500 || s == http.StatusTooManyRequests || s == http.StatusRequestTimeout
+}
+
 func (c *Client) list(path string) ([]byte, error) {
 	escaped, err := module.EscapePath(path)
 	if err != nil {
@@ -126,6 +155,17 @@ func (c *Client) mod(path string, ver string) ([]byte, error) {
 	return c.lookup(fmt.Sprintf("%s/@v/%v.mod", ep, ev))
 }
 
+func (c *Client) zip(path string, ver string) ([]byte, error) {
+	if err := module.Check(path, vv(ver)); err != nil {
+		return nil, err
+	}
+	ep, ev, err := escapePathAndVersion(path, ver)
+	if err != nil {
+		return nil, err
+	}
+	return c.lookup(fmt.Sprintf("%s/@v/%v.zip", ep, ev))
+}
+
 // escapePathAndVersion escapes the module path and version.
 func escapePathAndVersion(path, ver string) (ePath, eVersion string, err error) {
 	vv := vv(ver)
@@ -241,6 +281,37 @@ func (c *Client) versions(path string) ([]string, error) {
 	return vs, nil
 }
 
+// HasPackage reports whether the module at the given path and version
+// (with no leading "v" prefix) contains the package pkgPath, that is,
+// a directory with at least one non-test Go file.
+//
+// It downloads the module zip from the proxy, so it can be expensive
+// for large modules.
+func (c *Client) HasPackage(modPath, ver, pkgPath string) (_ bool, err error) {
+	defer derrors.Wrap(&err, "HasPackage(%s, %s, %s)", modPath, ver, pkgPath)
+
+	if pkgPath != modPath && !strings.HasPrefix(pkgPath, modPath+"/") {
+		return false, nil
+	}
+	b, err := c.zip(modPath, ver)
+	if err != nil {
+		return false, err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return false, err
+	}
+	// Files in a module zip are of the form "<module>@<version>/<file>".
+	dir := modPath + "@" + vv(ver) + strings.TrimPrefix(pkgPath, modPath)
+	for _, f := range zr.File {
+		if urlpath.Dir(f.Name) == dir &&
+			strings.HasSuffix(f.Name, ".go") && !strings.HasSuffix(f.Name, "_test.go") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 var errNoModuleFound = errors.New("no module found")
 
 // FindModule returns the longest directory prefix of path that
@@ -338,8 +338,54 @@ func TestModuleExists(t *testing.T) {
 	}
 }
 
+func TestHasPackage(t *testing.T) {
+	c, err := NewTestClient(t, *realProxy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tcs := []struct {
+		name string
+		pkg  string
+		want bool
+	}{
+		{
+			name: "root package",
+			pkg:  "rsc.io/quote",
+			want: true,
+		},
+		{
+			name: "only test files",
+			pkg:  "rsc.io/quote/buggy",
+			want: false,
+		},
+		{
+			name: "does not exist",
+			pkg:  "rsc.io/quote/nope",
+			want: false,
+		},
+		{
+			name: "not in module",
+			pkg:  "rsc.io/quotes",
+			want: false,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := c.HasPackage("rsc.io/quote", "1.5.2", tc.pkg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("HasPackage() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
 func TestCacheAndErrors(t *testing.T) {
-	okEndpoint, notFoundEndpoint := "endpoint", "not/found"
+	okEndpoint, notFoundEndpoint, unavailableEndpoint := "endpoint", "not/found", "unavailable"
 	okResponse := "response"
 	responses := map[string]*response{
 		okEndpoint: {
@@ -350,6 +396,10 @@ func TestCacheAndErrors(t *testing.T) {
 			Body:       "",
 			StatusCode: http.StatusNotFound,
 		},
+		unavailableEndpoint: {
+			Body:       "",
+			StatusCode: http.StatusServiceUnavailable,
+		},
 	}
 	c, cleanup := fakeClient(responses)
 	t.Cleanup(cleanup)
@@ -372,6 +422,13 @@ func TestCacheAndErrors(t *testing.T) {
 		t.Errorf("lookup(%q) succeeded, want error", notFoundEndpoint)
 	}
 
+	if _, err := c.lookup(notFoundEndpoint); errors.Is(err, ErrUnavailable) {
+		t.Errorf("lookup(%q) error = %v, want not ErrUnavailable", notFoundEndpoint, err)
+	}
+	if _, err := c.lookup(unavailableEndpoint); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("lookup(%q) error = %v, want ErrUnavailable", unavailableEndpoint, err)
+	}
+
 	want, got := responses, c.responses()
 	if diff := cmp.Diff(want, got); diff != "" {
 		t.Errorf("Responses() unexpected diff (want-, got+):\n%s", diff)
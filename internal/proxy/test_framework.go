@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"sync"
 	"testing"
+	"unicode/utf8"
 )
 
 // NewTestClient creates a new client for testing.
@@ -59,7 +60,11 @@ func NewTestClient(t *testing.T, update bool) (*Client, error) {
 // response is a representation of an HTTP response used to
 // facilitate testing.
 type response struct {
-	Body       string `json:"body,omitempty"`
+	Body string `json:"body,omitempty"`
+	// BinaryBody is used instead of Body for bodies that are not
+	// valid UTF-8 (such as module zips), which would otherwise be
+	// corrupted by JSON encoding.
+	BinaryBody []byte `json:"binary_body,omitempty"`
 	StatusCode int    `json:"status_code"`
 }
 
@@ -72,7 +77,11 @@ func fakeClient(endpointsToResponses map[string]*response) (c *Client, cleanup f
 			if r.Method == http.MethodGet &&
 				r.URL.Path == "/"+endpoint {
 				if response.StatusCode == http.StatusOK {
-					_, _ = w.Write([]byte(response.Body))
+					if response.BinaryBody != nil {
+						_, _ = w.Write(response.BinaryBody)
+					} else {
+						_, _ = w.Write([]byte(response.Body))
+					}
 				} else {
 					w.WriteHeader(response.StatusCode)
 				}
@@ -99,7 +108,11 @@ func (c *Client) responses() map[string]*response {
 		m[key] = &response{StatusCode: status}
 	}
 	for key, b := range c.cache.getData() {
-		m[key] = &response{Body: string(b), StatusCode: http.StatusOK}
+		if utf8.Valid(b) {
+			m[key] = &response{Body: string(b), StatusCode: http.StatusOK}
+		} else {
+			m[key] = &response{BinaryBody: b, StatusCode: http.StatusOK}
+		}
 	}
 	return m
 }
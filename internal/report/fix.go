@@ -7,24 +7,63 @@ package report
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"regexp"
-	"sort"
 	"strings"
+	"unicode"
 
 	"golang.org/x/exp/slices"
+	"golang.org/x/vulndb/internal/cveschema5"
+	"golang.org/x/vulndb/internal/derrors"
+	"golang.org/x/vulndb/internal/osv"
+	"golang.org/x/vulndb/internal/osvutils"
 	"golang.org/x/vulndb/internal/proxy"
 	"golang.org/x/vulndb/internal/version"
 )
 
 func (r *Report) Fix(pc *proxy.Client) {
+	r.fix(pc, func(error) {})
+}
+
+// FixStrict is like Fix, but returns an error if any step that uses
+// the proxy failed because the proxy was unavailable (see
+// proxy.ErrUnavailable). Values that the proxy reports don't exist are
+// left unchanged without error, as in Fix.
+//
+// FixStrict performs all the fixes it can even if there are errors,
+// and returns the first error.
+func (r *Report) FixStrict(pc *proxy.Client) (err error) {
+	defer derrors.Wrap(&err, "FixStrict(%q)", r.ID)
+
+	r.fix(pc, func(e error) {
+		if err == nil && errors.Is(e, proxy.ErrUnavailable) {
+			err = e
+		}
+	})
+	return err
+}
+
+// fix implements Fix, calling onErr with the errors from the proxy
+// that cause values to be left unchanged.
+func (r *Report) fix(pc *proxy.Client, onErr func(error)) {
 	for _, ref := range r.References {
-		ref.URL = fixURL(ref.URL)
+		ref.URL = normalizeURL(ref.URL)
+		if c := r.canonicalGHSALink(ref); c != "" {
+			ref.URL = c
+		}
+		if _, nvd, ok := aggregatorNVDLink(ref.URL); ok {
+			ref.URL = nvd
+		}
 	}
+	r.References = dedupeReferences(r.References)
 	for _, m := range r.Modules {
-		m.FixVersions(pc)
+		m.fixVersions(pc, onErr)
+		for _, p := range m.Packages {
+			p.Symbols = dedupe(p.Symbols)
+		}
 	}
 	fixLines := func(sp *string) {
-		*sp = fixLineLength(*sp, maxLineLength)
+		*sp = fixLineLength(fixWhitespace(normalizeNewlines(*sp)), maxLineLength)
 	}
 	fixLines(&r.Summary)
 	fixLines(&r.Description)
@@ -33,9 +72,111 @@ func (r *Report) Fix(pc *proxy.Client) {
 	}
 }
 
+// SetFixedCommit records that the vulnerability in module modulePath
+// of r was fixed in version v, by the commit at commitURL.
+//
+// It sets the fixed version of the module's last version range
+// (adding a range if there are none), and adds commitURL, normalized as
+// by Fix, as a FIX reference. If the reference is already present, its
+// type is set to FIX.
+//
+// It returns an error, and leaves r unchanged, if the module is not in
+// r, v is not a valid release version, commitURL does not look like a
+// link to a fix, the last range is already fixed at a different version,
+// or the resulting version ranges are inconsistent (for example, v is
+// not after the introduced version, or the module's vulnerable_at
+// version would no longer be affected).
+func (r *Report) SetFixedCommit(modulePath, v, commitURL string) (err error) {
+	defer derrors.Wrap(&err, "SetFixedCommit(%q, %q, %q)", modulePath, v, commitURL)
+
+	var m *Module
+	for _, mod := range r.Modules {
+		if mod.Module == modulePath {
+			m = mod
+			break
+		}
+	}
+	if m == nil {
+		return fmt.Errorf("module %s is not in report", modulePath)
+	}
+
+	v = version.TrimPrefix(v)
+	if !version.IsValid(v) || version.IsPseudo(v) {
+		return fmt.Errorf("%q is not a valid release version", v)
+	}
+	v = version.Canonical(v)
+
+	u := stripTrackingParams(fixURL(commitURL))
+	if pu, err := url.Parse(u); err != nil || pu.Host == "" || (pu.Scheme != "http" && pu.Scheme != "https") {
+		return fmt.Errorf("%q is not a valid absolute URL", commitURL)
+	}
+	if !isFix(u) {
+		return fmt.Errorf("%q does not look like a link to a fix", commitURL)
+	}
+
+	versions := slices.Clone(m.Versions)
+	if n := len(versions); n == 0 {
+		versions = append(versions, VersionRange{Fixed: v})
+	} else if f := versions[n-1].Fixed; f == "" {
+		versions[n-1].Fixed = v
+	} else if f != v {
+		return fmt.Errorf("module %s is already fixed in version %s", modulePath, f)
+	}
+	ranges := AffectedRanges(versions)
+	if err := osvutils.ValidateRanges(ranges); err != nil {
+		return fmt.Errorf("inconsistent versions: %w", err)
+	}
+	if m.VulnerableAt != "" {
+		if affected, err := osvutils.AffectsSemver(ranges, m.VulnerableAt); err == nil && !affected {
+			return fmt.Errorf("vulnerable_at version %s would not be affected", m.VulnerableAt)
+		}
+	}
+
+	m.Versions = versions
+	for _, ref := range r.References {
+		if ref.URL == u {
+			ref.Type = osv.ReferenceTypeFix
+			return nil
+		}
+	}
+	r.References = append(r.References, &Reference{Type: osv.ReferenceTypeFix, URL: u})
+	return nil
+}
+
+// dedupe returns the distinct elements of s, in the order of
+// their first occurrence.
+func dedupe(s []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, e := range s {
+		if !seen[e] {
+			seen[e] = true
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// dedupeReferences removes the references in refs that have the same
+// type and URL as an earlier one.
+func dedupeReferences(refs []*Reference) []*Reference {
+	seen := make(map[Reference]bool)
+	return slices.DeleteFunc(refs, func(ref *Reference) bool {
+		if seen[*ref] {
+			return true
+		}
+		seen[*ref] = true
+		return false
+	})
+}
+
 // FixVersions replaces each version with its canonical form (if possible),
 // sorts version ranges, and collects version ranges into a compact form.
 func (m *Module) FixVersions(pc *proxy.Client) {
+	m.fixVersions(pc, func(error) {})
+}
+
+func (m *Module) fixVersions(pc *proxy.Client, onErr func(error)) {
 	fixVersion := func(v string) string {
 		if v == "" {
 			return ""
@@ -43,6 +184,8 @@ func (m *Module) FixVersions(pc *proxy.Client) {
 		if version.IsCommitHash(v) {
 			if c, err := pc.CanonicalModuleVersion(m.Module, v); err == nil { // no error
 				v = c
+			} else {
+				onErr(fmt.Errorf("module %s: could not canonicalize version %s: %w", m.Module, v, err))
 			}
 		}
 		v = version.TrimPrefix(v)
@@ -58,22 +201,7 @@ func (m *Module) FixVersions(pc *proxy.Client) {
 	}
 	m.VulnerableAt = fixVersion(m.VulnerableAt)
 
-	sort.SliceStable(m.Versions, func(i, j int) bool {
-		intro, fixed := m.Versions[i].Introduced, m.Versions[i].Fixed
-		intro2, fixed2 := m.Versions[j].Introduced, m.Versions[j].Fixed
-		switch {
-		case intro != "" && intro2 != "":
-			return version.Before(intro, intro2)
-		case intro != "" && fixed2 != "":
-			return version.Before(intro, fixed2)
-		case fixed != "" && intro2 != "":
-			return version.Before(fixed, intro2)
-		case fixed != "" && fixed2 != "":
-			return version.Before(fixed, fixed2)
-		default:
-			return false
-		}
-	})
+	m.sortVersions()
 
 	// Remove duplicate version ranges.
 	m.Versions = slices.Compact(m.Versions)
@@ -81,12 +209,16 @@ func (m *Module) FixVersions(pc *proxy.Client) {
 	// Collect together version ranges that don't need to be separate,
 	// e.g:
 	// [ {Introduced: 1.1.0}, {Fixed: 1.2.0} ] becomes
-	// [ {Introduced: 1.1.0, Fixed: 1.2.0} ].
+	// [ {Introduced: 1.1.0, Fixed: 1.2.0} ], and
+	// [ {Fixed: 1.2.0}, {Introduced: 1.2.0, Fixed: 1.4.0} ] becomes
+	// [ {Fixed: 1.4.0} ].
 	for i := 0; i < len(m.Versions); i++ {
 		if i != 0 {
 			current, prev := m.Versions[i], m.Versions[i-1]
-			if (prev.Introduced != "" && prev.Fixed == "") &&
-				(current.Introduced == "" && current.Fixed != "") {
+			split := (prev.Introduced != "" && prev.Fixed == "") &&
+				(current.Introduced == "" && current.Fixed != "")
+			adjacent := prev.Fixed != "" && prev.Fixed == current.Introduced
+			if split || adjacent {
 				m.Versions[i-1].Fixed = current.Fixed
 				m.Versions = append(m.Versions[:i], m.Versions[i+1:]...)
 				i--
@@ -94,19 +226,22 @@ func (m *Module) FixVersions(pc *proxy.Client) {
 		}
 	}
 
-	m.fixVulnerableAt(pc)
+	m.fixVulnerableAt(pc, onErr)
 }
 
-func (m *Module) fixVulnerableAt(pc *proxy.Client) {
-	if m.VulnerableAt != "" {
+func (m *Module) fixVulnerableAt(pc *proxy.Client, onErr func(error)) {
+	if m.VulnerableAt != "" || m.IsFirstParty() {
+		// vulnerable_at can't be guessed for first-party modules.
 		return
 	}
 	// Don't attempt to guess if the given version ranges don't make sense.
 	if err := m.checkModVersions(pc); err != nil {
+		onErr(fmt.Errorf("module %s: could not guess vulnerable_at: %w", m.Module, err))
 		return
 	}
 	v, err := m.guessVulnerableAt(pc)
 	if err != nil {
+		onErr(fmt.Errorf("module %s: could not guess vulnerable_at: %w", m.Module, err))
 		return
 	}
 	m.VulnerableAt = v
@@ -131,7 +266,7 @@ func (m *Module) guessVulnerableAt(pc *proxy.Client) (v string, err error) {
 	if fixed == "" {
 		latest, err := pc.Latest(m.Module)
 		if err != nil || latest == "" {
-			return "", fmt.Errorf("no fix, but could not find latest version from proxy: %s", err)
+			return "", fmt.Errorf("no fix, but could not find latest version from proxy: %w", err)
 		}
 
 		return latest, nil
@@ -149,7 +284,7 @@ func (m *Module) guessVulnerableAt(pc *proxy.Client) (v string, err error) {
 	// Otherwise, find the version right before the fixed version.
 	vs, err := pc.Versions(m.Module)
 	if err != nil {
-		return "", fmt.Errorf("could not find versions from proxy: %s", err)
+		return "", fmt.Errorf("could not find versions from proxy: %w", err)
 	}
 	for i := len(vs) - 1; i >= 0; i-- {
 		if version.Before(vs[i], fixed) {
@@ -160,6 +295,26 @@ func (m *Module) guessVulnerableAt(pc *proxy.Client) (v string, err error) {
 	return "", errors.New("could not find tagged version less than fixed")
 }
 
+// normalizeNewlines returns a copy of s with "\r\n" line endings
+// (and any other carriage returns) replaced by "\n".
+func normalizeNewlines(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\r", "\n")
+}
+
+// fixWhitespace returns a copy of s with trailing whitespace removed
+// from each line, and tabs other than in leading indentation
+// replaced by spaces.
+func fixWhitespace(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		rest := strings.TrimLeft(line, " \t")
+		indent := line[:len(line)-len(rest)]
+		lines[i] = indent + strings.ReplaceAll(rest, "\t", " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
 // fixLineLength returns a copy of s with all lines trimmed to <=n characters
 // (with the exception of single-word lines).
 // It preserves paragraph breaks (indicated by "\n\n") and markdown-style list
@@ -202,6 +357,13 @@ func fixLineLength(s string, n int) string {
 	return result.String()
 }
 
+// gerritCLRegex matches links to a CL in the go repository on the Go
+// Gerrit server, such as https://go-review.googlesource.com/c/go/+/12345,
+// optionally with a patch set number. The CL number is captured. Links to
+// files within a CL are not matched, since rewriting them would lose
+// information.
+var gerritCLRegex = regexp.MustCompile(`^https?://go-review\.googlesource\.com/(?:#/)?c/go/\+/(\d+)(?:/\d*)?/?$`)
+
 var urlReplacements = []struct {
 	re   *regexp.Regexp
 	repl string
@@ -213,17 +375,107 @@ var urlReplacements = []struct {
 
 	`https://groups.google.com/g/$1/c/$2/m/$3`,
 }, {
-	regexp.MustCompile(`.*github.com/golang/go/issues`),
-	`https://go.dev/issue`,
+	// Only links to a single issue have a go.dev form; the issue number
+	// (and any fragment, such as a comment anchor) is kept.
+	regexp.MustCompile(`^(?:https?://)?(?:www\.)?github\.com/golang/go/issues/(\d+)\b`),
+	`https://go.dev/issue/$1`,
+}, {
+	// The go repository on GitHub is a mirror of the one on
+	// go.googlesource.com. (Other repositories under github.com/golang
+	// are not all mirrors, so they are left alone.)
+	regexp.MustCompile(`^(?:https?://)?(?:www\.)?github\.com/golang/go/commit/([0-9a-f]+)\b`),
+	`https://go.googlesource.com/go/+/$1`,
 }, {
-	regexp.MustCompile(`.*github.com/golang/go/commit`),
-	`https://go.googlesource.com/+`,
+	gerritCLRegex,
+	`https://go.dev/cl/$1`,
+}, {
+	// Legacy NVD detail pages.
+	regexp.MustCompile(`^https?://(?:web\.)?nvd\.nist\.gov/(?:vuln/detail/|view/vuln/detail\?vulnId=|nvd\.cfm\?cvename=)(` + cveschema5.Regex + `)$`),
+	`https://nvd.nist.gov/vuln/detail/$1`,
 },
 }
 
+// normalizeURL returns u in the form that Fix rewrites it to,
+// before any rewrites that depend on the rest of the report.
+func normalizeURL(u string) string {
+	return stripTrackingParams(fixURL(stripEmptyFragment(strings.TrimFunc(u, isSpaceOrControl))))
+}
+
+// isSpaceOrControl reports whether r is a whitespace or non-printable
+// character (such as a zero-width space), which can't appear in a
+// well-formed URL. Such characters are often left at the ends of URLs
+// copied from documents or emails.
+func isSpaceOrControl(r rune) bool {
+	return unicode.IsSpace(r) || !unicode.IsPrint(r)
+}
+
 func fixURL(u string) string {
 	for _, repl := range urlReplacements {
 		u = repl.re.ReplaceAllString(u, repl.repl)
 	}
 	return u
 }
+
+// stripEmptyFragment removes a trailing "#" from the URL u, which has
+// no effect on the linked page.
+//
+// Non-empty fragments are kept, even if another reference links to the
+// same page without one, since it isn't clear which of the two is
+// intended.
+func stripEmptyFragment(u string) string {
+	return strings.TrimSuffix(u, "#")
+}
+
+// trackingParams are query parameters added to URLs for tracking
+// purposes, which have no meaning to the linked page.
+var trackingParams = map[string]bool{
+	"gclid":      true,
+	"dclid":      true,
+	"fbclid":     true,
+	"msclkid":    true,
+	"yclid":      true,
+	"igshid":     true,
+	"mc_cid":     true,
+	"mc_eid":     true,
+	"_ga":        true,
+	"_gl":        true,
+	"sessionid":  true,
+	"session_id": true,
+	"jsessionid": true,
+	"phpsessid":  true,
+}
+
+func isTrackingParam(key string) bool {
+	key = strings.ToLower(key)
+	return strings.HasPrefix(key, "utm_") || trackingParams[key]
+}
+
+// stripTrackingParams removes any tracking query parameters (such as
+// "utm_source") from the URL u. All other parts of the URL, including any
+// other query parameters, are left untouched.
+func stripTrackingParams(u string) string {
+	rest, fragment, hasFragment := strings.Cut(u, "#")
+	base, query, hasQuery := strings.Cut(rest, "?")
+	if !hasQuery {
+		return u
+	}
+	params := strings.Split(query, "&")
+	var kept []string
+	for _, param := range params {
+		key, _, _ := strings.Cut(param, "=")
+		if k, err := url.QueryUnescape(key); err == nil && isTrackingParam(k) {
+			continue
+		}
+		kept = append(kept, param)
+	}
+	if len(kept) == len(params) {
+		return u
+	}
+	if len(kept) > 0 {
+		base += "?" + strings.Join(kept, "&")
+	}
+	if hasFragment {
+		base += "#" + fragment
+	}
+	return base
+}
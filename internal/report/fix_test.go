@@ -5,9 +5,14 @@
 package report
 
 import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vulndb/internal/osv"
 	"golang.org/x/vulndb/internal/proxy"
 )
 
@@ -30,8 +35,17 @@ func TestFix(t *testing.T) {
 					{
 						Fixed: "go1.18.5",
 					},
+					{
+						// Adjacent to the previous range.
+						Introduced: "go1.18.5",
+						Fixed:      "go1.18.8",
+					},
 				},
 				VulnerableAt: "go1.20",
+				Packages: []*Package{{
+					Package: "net/http",
+					Symbols: []string{"Get", "Client.Do", "Get", "Post", "Client.Do"},
+				}},
 			},
 			{
 				Module: "golang.org/x/vulndb",
@@ -42,10 +56,23 @@ func TestFix(t *testing.T) {
 			},
 		},
 		Description: "A long form description of the problem that will be broken up into multiple lines so it is more readable.",
+		GHSAs:       []string{"GHSA-xxxx-yyyy-zzzz"},
 		References: []*Reference{
 			{
 				URL: "https://github.com/golang/go/issues/123",
 			},
+			{
+				// Same as above, after fixing.
+				URL: "https://github.com/golang/go/issues/123#",
+			},
+			{
+				Type: osv.ReferenceTypeAdvisory,
+				URL:  "https://github.com/golang/vulndb/security/advisories/GHSA-xxxx-yyyy-zzzz",
+			},
+			{
+				Type: osv.ReferenceTypeWeb,
+				URL:  "https://www.cvedetails.com/cve/CVE-2023-1234/",
+			},
 		},
 	}
 	want := Report{
@@ -54,7 +81,7 @@ func TestFix(t *testing.T) {
 				Module: "std",
 				Versions: []VersionRange{
 					{
-						Fixed: "1.18.5",
+						Fixed: "1.18.8",
 					},
 					{
 						Introduced: "1.19.0",
@@ -66,6 +93,10 @@ func TestFix(t *testing.T) {
 					},
 				},
 				VulnerableAt: "1.20.0",
+				Packages: []*Package{{
+					Package: "net/http",
+					Symbols: []string{"Get", "Client.Do", "Post"},
+				}},
 			},
 			{
 				Module: "golang.org/x/vulndb",
@@ -76,10 +107,19 @@ func TestFix(t *testing.T) {
 			},
 		},
 		Description: "A long form description of the problem that will be broken up into multiple\nlines so it is more readable.",
+		GHSAs:       []string{"GHSA-xxxx-yyyy-zzzz"},
 		References: []*Reference{
 			{
 				URL: "https://go.dev/issue/123",
 			},
+			{
+				Type: osv.ReferenceTypeAdvisory,
+				URL:  "https://github.com/advisories/GHSA-xxxx-yyyy-zzzz",
+			},
+			{
+				Type: osv.ReferenceTypeWeb,
+				URL:  "https://nvd.nist.gov/vuln/detail/CVE-2023-1234",
+			},
 		},
 	}
 
@@ -180,6 +220,207 @@ broken up
 	}
 }
 
+func TestFixWhitespace(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		unfixed string
+		want    string
+	}{
+		{
+			name:    "ok",
+			unfixed: "A line.\n\n  An indented line.",
+			want:    "A line.\n\n  An indented line.",
+		},
+		{
+			name:    "trailing whitespace",
+			unfixed: "A line. \t\nAnother line.  ",
+			want:    "A line.\nAnother line.",
+		},
+		{
+			name:    "tabs",
+			unfixed: "\tIndented\twith tabs.",
+			want:    "\tIndented with tabs.",
+		},
+		{
+			name:    "carriage returns",
+			unfixed: "A line.\r\nAnother line.\rA third line.",
+			want:    "A line.\nAnother line.\nA third line.",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := fixWhitespace(normalizeNewlines(tc.unfixed))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("fixWhitespace() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFixURL(t *testing.T) {
+	for _, tc := range []struct {
+		url  string
+		want string
+	}{
+		{
+			url:  "https://nvd.nist.gov/vuln/detail/CVE-2020-1234",
+			want: "https://nvd.nist.gov/vuln/detail/CVE-2020-1234",
+		},
+		{
+			url:  "http://nvd.nist.gov/vuln/detail/CVE-2020-1234",
+			want: "https://nvd.nist.gov/vuln/detail/CVE-2020-1234",
+		},
+		{
+			url:  "https://web.nvd.nist.gov/vuln/detail/CVE-2020-1234",
+			want: "https://nvd.nist.gov/vuln/detail/CVE-2020-1234",
+		},
+		{
+			url:  "https://web.nvd.nist.gov/view/vuln/detail?vulnId=CVE-2020-12345",
+			want: "https://nvd.nist.gov/vuln/detail/CVE-2020-12345",
+		},
+		{
+			url:  "http://nvd.nist.gov/nvd.cfm?cvename=CVE-2020-1234",
+			want: "https://nvd.nist.gov/vuln/detail/CVE-2020-1234",
+		},
+		{
+			// Not a detail page.
+			url:  "https://nvd.nist.gov/vuln/search",
+			want: "https://nvd.nist.gov/vuln/search",
+		},
+		{
+			url:  "https://github.com/golang/go/issues/12345",
+			want: "https://go.dev/issue/12345",
+		},
+		{
+			url:  "http://www.github.com/golang/go/issues/12345#issuecomment-1",
+			want: "https://go.dev/issue/12345#issuecomment-1",
+		},
+		{
+			// Not a single issue.
+			url:  "https://github.com/golang/go/issues?q=is%3Aissue+label%3ASecurity",
+			want: "https://github.com/golang/go/issues?q=is%3Aissue+label%3ASecurity",
+		},
+		{
+			// Not the go repository.
+			url:  "https://github.com/golang/gofrontend/issues/12345",
+			want: "https://github.com/golang/gofrontend/issues/12345",
+		},
+		{
+			url:  "https://github.com/golang/go/commit/abcdef",
+			want: "https://go.googlesource.com/go/+/abcdef",
+		},
+		{
+			url:  "github.com/golang/go/commit/0123456789abcdef0123456789abcdef01234567",
+			want: "https://go.googlesource.com/go/+/0123456789abcdef0123456789abcdef01234567",
+		},
+		{
+			// Not the go repository.
+			url:  "https://github.com/golang/protobuf/commit/abcdef",
+			want: "https://github.com/golang/protobuf/commit/abcdef",
+		},
+		{
+			url:  "https://go-review.googlesource.com/c/go/+/12345",
+			want: "https://go.dev/cl/12345",
+		},
+		{
+			url:  "https://go-review.googlesource.com/c/go/+/12345/2/",
+			want: "https://go.dev/cl/12345",
+		},
+		{
+			url:  "https://go-review.googlesource.com/#/c/go/+/12345",
+			want: "https://go.dev/cl/12345",
+		},
+		{
+			// A link to a file within a CL.
+			url:  "https://go-review.googlesource.com/c/go/+/94838/9/src/net/http/server.go#1906",
+			want: "https://go-review.googlesource.com/c/go/+/94838/9/src/net/http/server.go#1906",
+		},
+		{
+			// Not in the go repository.
+			url:  "https://go-review.googlesource.com/c/net/+/136575",
+			want: "https://go-review.googlesource.com/c/net/+/136575",
+		},
+	} {
+		if got := fixURL(tc.url); got != tc.want {
+			t.Errorf("fixURL(%q) = %q, want %q", tc.url, got, tc.want)
+		}
+	}
+}
+
+func TestStripTrackingParams(t *testing.T) {
+	tcs := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{
+			name: "no query",
+			url:  "https://example.com/a#b",
+			want: "https://example.com/a#b",
+		},
+		{
+			name: "meaningful query",
+			url:  "https://github.com/owner/repo/issues?q=is%3Aopen+label%3Abug&page=2",
+			want: "https://github.com/owner/repo/issues?q=is%3Aopen+label%3Abug&page=2",
+		},
+		{
+			name: "only tracking",
+			url:  "https://example.com/post?utm_source=twitter&utm_medium=social",
+			want: "https://example.com/post",
+		},
+		{
+			name: "mixed",
+			url:  "https://example.com/post?id=3&fbclid=abc&UTM_Campaign=x&page=1#section",
+			want: "https://example.com/post?id=3&page=1#section",
+		},
+		{
+			name: "session",
+			url:  "https://example.com/advisory?jsessionid=12345",
+			want: "https://example.com/advisory",
+		},
+		{
+			name: "similar but meaningful",
+			url:  "https://example.com/a?utm=1&session=2&gclid_info=3",
+			want: "https://example.com/a?utm=1&session=2&gclid_info=3",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := stripTrackingParams(tc.url); got != tc.want {
+				t.Errorf("stripTrackingParams(%q) = %q, want %q", tc.url, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeURL(t *testing.T) {
+	for _, tc := range []struct {
+		url  string
+		want string
+	}{
+		{
+			url:  "https://example.com/a",
+			want: "https://example.com/a",
+		},
+		{
+			url:  " \thttps://example.com/a\n",
+			want: "https://example.com/a",
+		},
+		{
+			url:  "https://example.com/a\u200b",
+			want: "https://example.com/a",
+		},
+		{
+			// Characters in the middle are left for the author to fix.
+			url:  "https://example.com/a b",
+			want: "https://example.com/a b",
+		},
+	} {
+		if got := normalizeURL(tc.url); got != tc.want {
+			t.Errorf("normalizeURL(%q) = %q, want %q", tc.url, got, tc.want)
+		}
+	}
+}
+
 func TestGuessVulnerableAt(t *testing.T) {
 	pc, err := proxy.NewTestClient(t, *realProxy)
 	if err != nil {
@@ -221,3 +462,192 @@ func TestGuessVulnerableAt(t *testing.T) {
 		})
 	}
 }
+
+func TestSetFixedCommit(t *testing.T) {
+	const (
+		mod    = "github.com/owner/repo"
+		commit = "https://github.com/owner/repo/commit/0123456789abcdef0123456789abcdef01234567"
+	)
+	newReport := func(versions []VersionRange, vulnerableAt string, refs ...*Reference) *Report {
+		return &Report{
+			Modules: []*Module{{
+				Module:       mod,
+				Versions:     versions,
+				VulnerableAt: vulnerableAt,
+			}},
+			References: refs,
+		}
+	}
+	fixRef := &Reference{Type: osv.ReferenceTypeFix, URL: commit}
+
+	for _, test := range []struct {
+		desc      string
+		r         *Report
+		module    string
+		version   string
+		commitURL string
+		want      *Report
+		wantErr   string
+	}{
+		{
+			desc:      "no ranges",
+			r:         newReport(nil, "1.1.0"),
+			version:   "v1.2.0",
+			commitURL: commit,
+			want:      newReport([]VersionRange{{Fixed: "1.2.0"}}, "1.1.0", fixRef),
+		},
+		{
+			desc:      "open range",
+			r:         newReport([]VersionRange{{Introduced: "1.0.0"}}, ""),
+			version:   "1.2.0",
+			commitURL: commit + "?utm_source=x",
+			want:      newReport([]VersionRange{{Introduced: "1.0.0", Fixed: "1.2.0"}}, "", fixRef),
+		},
+		{
+			desc:      "existing reference",
+			r:         newReport([]VersionRange{{Introduced: "1.0.0"}}, "", &Reference{Type: osv.ReferenceTypeWeb, URL: commit}),
+			version:   "1.2.0",
+			commitURL: commit,
+			want:      newReport([]VersionRange{{Introduced: "1.0.0", Fixed: "1.2.0"}}, "", fixRef),
+		},
+		{
+			desc:      "unknown module",
+			r:         newReport(nil, ""),
+			module:    "example.com/other",
+			version:   "1.2.0",
+			commitURL: commit,
+			wantErr:   "module example.com/other is not in report",
+		},
+		{
+			desc:      "pseudo-version",
+			r:         newReport(nil, ""),
+			version:   "0.0.0-20230101000000-0123456789ab",
+			commitURL: commit,
+			wantErr:   "is not a valid release version",
+		},
+		{
+			desc:      "not a fix",
+			r:         newReport(nil, ""),
+			version:   "1.2.0",
+			commitURL: "https://github.com/owner/repo",
+			wantErr:   "does not look like a link to a fix",
+		},
+		{
+			desc:      "already fixed",
+			r:         newReport([]VersionRange{{Fixed: "1.1.0"}}, ""),
+			version:   "1.2.0",
+			commitURL: commit,
+			wantErr:   "already fixed in version 1.1.0",
+		},
+		{
+			desc:      "before introduced",
+			r:         newReport([]VersionRange{{Introduced: "1.3.0"}}, ""),
+			version:   "1.2.0",
+			commitURL: commit,
+			wantErr:   "inconsistent versions",
+		},
+		{
+			desc:      "vulnerable_at not affected",
+			r:         newReport(nil, "1.2.0"),
+			version:   "1.2.0",
+			commitURL: commit,
+			wantErr:   "vulnerable_at version 1.2.0 would not be affected",
+		},
+	} {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			module := test.module
+			if module == "" {
+				module = mod
+			}
+			before := *test.r.Modules[0]
+			err := test.r.SetFixedCommit(module, test.version, test.commitURL)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("got error %v, want error containing %q", err, test.wantErr)
+				}
+				if diff := cmp.Diff(&before, test.r.Modules[0]); diff != "" {
+					t.Errorf("module changed on error (-before, +after):\n%s", diff)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.want, test.r); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// errTransport is an http.RoundTripper that always fails.
+type errTransport struct{}
+
+func (errTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("network is unreachable")
+}
+
+func TestFixStrict(t *testing.T) {
+	const hash = "0cbf4ffdb4e70fce663ec8d59198745b04e7801b"
+	unreachable := proxy.NewClient(&http.Client{Transport: errTransport{}}, "https://proxy.example.com")
+	notFound := httptest.NewServer(http.NotFoundHandler())
+	defer notFound.Close()
+
+	for _, test := range []struct {
+		desc            string
+		pc              *proxy.Client
+		m               *Module
+		wantUnavailable bool
+	}{
+		{
+			desc: "nothing to fix",
+			pc:   unreachable,
+			m: &Module{
+				Module:       "std",
+				Versions:     []VersionRange{{Fixed: "1.20.1"}},
+				VulnerableAt: "1.20.0",
+			},
+		},
+		{
+			desc: "commit hash with unreachable proxy",
+			pc:   unreachable,
+			m: &Module{
+				Module:       "golang.org/x/vulndb",
+				Versions:     []VersionRange{{Introduced: hash}},
+				VulnerableAt: "1.0.0",
+			},
+			wantUnavailable: true,
+		},
+		{
+			desc: "vulnerable_at with unreachable proxy",
+			pc:   unreachable,
+			m: &Module{
+				Module:   "golang.org/x/vulndb",
+				Versions: []VersionRange{{Fixed: "1.2.0"}},
+			},
+			wantUnavailable: true,
+		},
+		{
+			desc: "commit hash not found",
+			pc:   proxy.NewClient(notFound.Client(), notFound.URL),
+			m: &Module{
+				Module:       "golang.org/x/vulndb",
+				Versions:     []VersionRange{{Introduced: hash}},
+				VulnerableAt: "1.0.0",
+			},
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			r := &Report{ID: "GO-0000-0000", Modules: []*Module{test.m}}
+			err := r.FixStrict(test.pc)
+			if test.wantUnavailable {
+				if !errors.Is(err, proxy.ErrUnavailable) {
+					t.Errorf("FixStrict() error = %v, want %v", err, proxy.ErrUnavailable)
+				}
+			} else if err != nil {
+				t.Errorf("FixStrict() error = %v, want nil", err)
+			}
+		})
+	}
+}
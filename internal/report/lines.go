@@ -0,0 +1,89 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package report
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/vulndb/internal/derrors"
+	"gopkg.in/yaml.v3"
+)
+
+// fieldLines maps the paths of the fields of a YAML report to their
+// lines. A path is the name of a top-level field, followed by ".name"
+// for each nested field and "[i]" for each element of a list, as in
+// "summary", "cve_metadata.id" and "modules[0].packages[1]". These are
+// the paths in LintIssue.Field.
+//
+// The line of a field is the line of its key, and the line of a list
+// element is the line on which the element starts.
+type fieldLines map[string]int
+
+// readFieldLines reads the YAML report in filename and returns the
+// lines of its fields.
+func readFieldLines(filename string) (_ fieldLines, err error) {
+	defer derrors.Wrap(&err, "readFieldLines(%q)", filename)
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var doc yaml.Node
+	if err := yaml.NewDecoder(f).Decode(&doc); err != nil {
+		return nil, err
+	}
+	fl := make(fieldLines)
+	if len(doc.Content) > 0 {
+		fl.add("", doc.Content[0])
+	}
+	return fl, nil
+}
+
+// add adds the lines of the fields in n, which is the value of the
+// field at path, to fl.
+func (fl fieldLines) add(path string, n *yaml.Node) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			p := k.Value
+			if path != "" {
+				p = path + "." + p
+			}
+			fl[p] = k.Line
+			fl.add(p, v)
+		}
+	case yaml.SequenceNode:
+		for i, c := range n.Content {
+			p := fmt.Sprintf("%s[%d]", path, i)
+			fl[p] = c.Line
+			fl.add(p, c)
+		}
+	}
+}
+
+// moduleField returns the path of the module of a report at index i.
+func moduleField(i int) string {
+	return fmt.Sprintf("modules[%d]", i)
+}
+
+// packageField returns the path of the package at index j of the
+// module of a report at index i.
+func packageField(i, j int) string {
+	return fmt.Sprintf("%s.packages[%d]", moduleField(i), j)
+}
+
+// referenceField returns the path of ref, which must be one of the
+// references of r, or "" if it is not.
+func (r *Report) referenceField(ref *Reference) string {
+	for i, rr := range r.References {
+		if rr == ref {
+			return fmt.Sprintf("references[%d]", i)
+		}
+	}
+	return ""
+}
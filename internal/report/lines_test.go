@@ -0,0 +1,129 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const linesYAML = `id: GO-0000-0001
+modules:
+    - module: example.com/m
+      versions:
+        - fixed: 1.2.0
+      packages:
+        - package: example.com/m/a
+        - package: example.com/m/a/b
+    - module: example.com/n
+summary: A summary.
+description: A description.
+cve_metadata:
+    id: CVE-0000-0001
+    cwe: 'CWE-000: TODO'
+references:
+    - fix: https://example.com/m/commit/1
+    - web: https://example.com/advisory?utm_source=x
+`
+
+func TestFieldLines(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "GO-0000-0001.yaml")
+	if err := os.WriteFile(filename, []byte(linesYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fl, err := readFieldLines(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		field string
+		want  int
+	}{
+		{"id", 1},
+		{"modules", 2},
+		{"modules[0]", 3},
+		{"modules[0].versions[0].fixed", 5},
+		{"modules[0].packages[0]", 7},
+		{"modules[0].packages[1]", 8},
+		{"modules[1]", 9},
+		{"summary", 10},
+		{"cve_metadata.cwe", 14},
+		{"references[1]", 17},
+		// Missing fields, and issues that are not about a field,
+		// have no line.
+		{"cve_metadata.description", 0},
+		{"modules[2]", 0},
+		{"", 0},
+	} {
+		if got := fl[test.field]; got != test.want {
+			t.Errorf("line of %q = %d, want %d", test.field, got, test.want)
+		}
+	}
+}
+
+func TestLintIssueFields(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "GO-0000-0001.yaml")
+	if err := os.WriteFile(filename, []byte(linesYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := Read(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string) // messages to fields
+	for _, iss := range r.lint(nil, nil) {
+		got[iss.Msg] = iss.Field
+	}
+	const noSymbols = "has no symbols listed; the entire package will be treated as vulnerable — confirm this is intended (and set whole_package)"
+	want := map[string]string{
+		"summary should not end in a period (should be a phrase, not a sentence)":                                                                               "summary",
+		`example.com/m: missing skip_fix and vulnerable_at: "example.com/m/a"`:                                                                                  "modules[0].packages[0]",
+		"example.com/m: package example.com/m/a " + noSymbols:                                                                                                   "modules[0].packages[0]",
+		`example.com/m: missing skip_fix and vulnerable_at: "example.com/m/a/b"`:                                                                                "modules[0].packages[1]",
+		"example.com/m: package example.com/m/a/b " + noSymbols:                                                                                                 "modules[0].packages[1]",
+		"example.com/n: module example.com/n has no version ranges; all versions will be considered affected — confirm this is intended (and set all_versions)": "modules[1]",
+		"cve_metadata.cwe contains a TODO":                                                                                                                      "cve_metadata.cwe",
+		"cve_metadata.description is required for self-assigned CVEs":                                                                                           "cve_metadata.description",
+		`"https://example.com/advisory?utm_source=x": reference URL contains tracking parameters`:                                                               "references[1]",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("fields mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestLintIssueString(t *testing.T) {
+	for _, test := range []struct {
+		iss  LintIssue
+		want string
+	}{
+		{
+			iss:  LintIssue{Severity: SeverityError, Msg: "missing summary"},
+			want: "missing summary",
+		},
+		{
+			iss:  LintIssue{File: "data/reports/GO-2023-0001.yaml", Severity: SeverityError, Msg: "missing summary"},
+			want: "data/reports/GO-2023-0001.yaml: missing summary",
+		},
+		{
+			iss:  LintIssue{File: "data/reports/GO-2023-0001.yaml", Line: 12, Severity: SeverityError, Msg: "summary contains a TODO"},
+			want: "data/reports/GO-2023-0001.yaml:12: summary contains a TODO",
+		},
+		{
+			iss:  LintIssue{File: "data/reports/GO-2023-0001.yaml", Line: 3, Severity: SeverityWarning, Msg: "m: module path casing may be wrong: M"},
+			want: "data/reports/GO-2023-0001.yaml:3: warning: m: module path casing may be wrong: M",
+		},
+		{
+			iss:  LintIssue{File: "data/reports/GO-2023-0001.yaml", Line: 3, Severity: SeverityWarning, Msg: "m: module path casing may be wrong: M", Code: "module-path-casing"},
+			want: "data/reports/GO-2023-0001.yaml:3: warning: m: module path casing may be wrong: M [module-path-casing]",
+		},
+	} {
+		if got := test.iss.String(); got != test.want {
+			t.Errorf("%+v.String() = %q, want %q", test.iss, got, test.want)
+		}
+	}
+}
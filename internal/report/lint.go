@@ -5,13 +5,18 @@
 package report
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"go/token"
+	"net/http"
 	"net/url"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
+	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
 	"golang.org/x/mod/module"
 	"golang.org/x/vulndb/internal/cveschema5"
@@ -21,19 +26,54 @@ import (
 	"golang.org/x/vulndb/internal/osvutils"
 	"golang.org/x/vulndb/internal/proxy"
 	"golang.org/x/vulndb/internal/stdlib"
+	"golang.org/x/vulndb/internal/version"
 )
 
+// checkModVersions checks that each version in m's version ranges
+// exists, and that m is the canonical module path at that version.
+//
+// Each version must be resolvable by the proxy. The proxy's list of
+// released versions of the module, which is fetched once per module,
+// is only used to explain why a tagged version can't be resolved: the
+// list is not complete, since tags that were deleted still resolve but
+// are no longer listed.
+//
+// Issues are reported with the module path as a prefix (see
+// addPkgIssue in lint), so the messages don't repeat it.
 func (m *Module) checkModVersions(pc *proxy.Client) error {
 	var notFound []string
+	var notReleased []string
+	var predates []string
 	var nonCanonical []string
+	// If the version list can't be fetched (for example, because
+	// m.Module is not canonical), fall back to checking that each
+	// version can be resolved.
+	released, _ := pc.Versions(m.Module)
 	for _, vr := range m.Versions {
-		for _, v := range []string{vr.Introduced, vr.Fixed} {
+		for _, fv := range []struct{ field, v string }{
+			{"introduced", vr.Introduced},
+			{"fixed", vr.Fixed},
+		} {
+			v := fv.v
 			if v == "" {
 				continue
 			}
 			c, err := pc.CanonicalModulePath(m.Module, v)
+			if errors.Is(err, proxy.ErrUnavailable) {
+				return fmt.Errorf("could not check version %s: %w", v, err)
+			}
 			if err != nil {
-				notFound = append(notFound, v)
+				switch {
+				case !version.IsValid(v) || version.IsPseudo(v) ||
+					released == nil || slices.Contains(released, v):
+					notFound = append(notFound, v)
+				case len(released) > 0 && version.Before(v, released[0]):
+					// Pseudo-versions may legitimately predate the
+					// first release, but release versions can't.
+					predates = append(predates, fmt.Sprintf("%s version %s predates the module's first release %s", fv.field, v, released[0]))
+				default:
+					notReleased = append(notReleased, fmt.Sprintf("%s version %s", fv.field, v))
+				}
 				continue
 			}
 			if c != m.Module {
@@ -41,55 +81,347 @@ func (m *Module) checkModVersions(pc *proxy.Client) error {
 			}
 		}
 	}
-	var sb strings.Builder
-	nf, nc := len(notFound), len(nonCanonical)
-	if nf > 0 {
-		if nf == 1 {
-			sb.WriteString(fmt.Sprintf("version %s does not exist", notFound[0]))
-		} else {
-			sb.WriteString(fmt.Sprintf("%d versions do not exist: %s", nf, strings.Join(notFound, ", ")))
-		}
+	var parts []string
+	switch nf := len(notFound); {
+	case nf == 1:
+		parts = append(parts, fmt.Sprintf("version %s does not exist", notFound[0]))
+	case nf > 1:
+		parts = append(parts, fmt.Sprintf("%d versions do not exist: %s", nf, strings.Join(notFound, ", ")))
+	}
+	switch nr := len(notReleased); {
+	case nr == 1:
+		parts = append(parts, fmt.Sprintf("%s is not a released version", notReleased[0]))
+	case nr > 1:
+		parts = append(parts, fmt.Sprintf("%d versions are not released versions: %s", nr, strings.Join(notReleased, ", ")))
+	}
+	parts = append(parts, predates...)
+	if nc := len(nonCanonical); nc > 0 {
+		parts = append(parts, fmt.Sprintf("module is not canonical at %d version(s):\n%s", nc, strings.Join(nonCanonical, "\n")))
+	}
+	if len(parts) > 0 {
+		return errors.New(strings.Join(parts, " and "))
+	}
+	return nil
+}
+
+// checkVulnerableAt checks that m's vulnerable_at version is a version
+// of m.Module that the proxy can serve. Symbols are derived by requiring
+// the module at that version, which otherwise fails with a less clear
+// error from the go command.
+//
+// A version that is only released with a +incompatible suffix is
+// reported with addWarning, since the go command rejects it as written
+// but the intended version is clear.
+func (m *Module) checkVulnerableAt(pc *proxy.Client, addWarning func(string)) error {
+	v := m.VulnerableAt
+	if v == "" {
+		return nil
+	}
+	// The version list is cached by pc, and is shared with
+	// checkModVersions.
+	if released, err := pc.Versions(m.Module); err == nil && slices.Contains(released, v) {
+		return nil
 	}
-	if nc > 0 {
-		if nf > 0 {
-			sb.WriteString(" and ")
+	// Versions that are not listed, such as pseudo-versions,
+	// may still resolve.
+	_, err := pc.CanonicalModuleVersion(m.Module, v)
+	if errors.Is(err, proxy.ErrUnavailable) {
+		return fmt.Errorf("could not check vulnerable_at version %s: %w", v, err)
+	}
+	if err != nil {
+		if !strings.HasSuffix(v, "+incompatible") {
+			if _, err := pc.CanonicalModuleVersion(m.Module, v+"+incompatible"); err == nil {
+				addWarning(fmt.Sprintf("vulnerable_at %s should be %s+incompatible", v, v))
+				return nil
+			}
 		}
-		sb.WriteString(fmt.Sprintf("module is not canonical at %d version(s):\n%s", nc, strings.Join(nonCanonical, "\n")))
+		return fmt.Errorf("vulnerable_at %s is not a released version", v)
+	}
+	return nil
+}
+
+// checkModPath checks that m.Module is the canonical path of the module
+// at its latest version. checkModVersions checks the path at each of
+// the versions of m, so this is only needed if m has no versions.
+func (m *Module) checkModPath(pc *proxy.Client) error {
+	latest, err := pc.Latest(m.Module)
+	if errors.Is(err, proxy.ErrUnavailable) {
+		return fmt.Errorf("could not check module path: %w", err)
+	}
+	if err != nil {
+		return fmt.Errorf("module %s has no latest version", m.Module)
+	}
+	c, err := pc.CanonicalModulePath(m.Module, latest)
+	if errors.Is(err, proxy.ErrUnavailable) {
+		return fmt.Errorf("could not check module path: %w", err)
 	}
-	if s := sb.String(); s != "" {
-		return errors.New(s)
+	if err != nil {
+		return fmt.Errorf("could not check module path at latest version %s: %v", latest, err)
+	}
+	if c != m.Module {
+		return fmt.Errorf("module is not canonical at latest version %s (canonical:%s)", latest, c)
 	}
 	return nil
 }
 
-func (m *Module) lintStdLib(addPkgIssue func(string)) {
-	if len(m.Packages) == 0 {
-		addPkgIssue("missing package")
+// checkPackages checks that each of m's packages exists in m
+// at m's vulnerable_at version. Issues are reported with the index
+// of the package in m.Packages.
+func (m *Module) checkPackages(pc *proxy.Client, addPackageIssue func(int, string)) {
+	if m.VulnerableAt == "" {
+		return
 	}
-	for _, p := range m.Packages {
+	for j, p := range m.Packages {
+		if p.Package == "" {
+			continue
+		}
+		ok, err := pc.HasPackage(m.Module, m.VulnerableAt, p.Package)
+		if err != nil {
+			addPackageIssue(j, fmt.Sprintf("could not check if package %s exists: %v", p.Package, err))
+			continue
+		}
+		if !ok {
+			addPackageIssue(j, fmt.Sprintf("package %s does not exist at v%s", p.Package, m.VulnerableAt))
+		}
+	}
+}
+
+// lintIntroduced warns if the introduced version of m's first version
+// range is redundant, because it is the smallest version that m's module
+// path allows (0.0.0, or N.0.0 for a module path ending in /vN).
+func (m *Module) lintIntroduced(addPkgWarning func(string)) {
+	if len(m.Versions) == 0 {
+		return
+	}
+	if intro := m.Versions[0].Introduced; intro != "" && intro == initialVersion(m.Module) {
+		addPkgWarning(fmt.Sprintf("introduced version %s is redundant; omit it to include all versions of %s", intro, m.Module))
+	}
+}
+
+// lintPrereleaseFixed warns about fixed versions of m that are
+// prereleases, since users of stable releases don't have the fix.
+func (m *Module) lintPrereleaseFixed(addPkgWarning func(string)) {
+	for _, vr := range m.Versions {
+		if version.IsPrerelease(vr.Fixed) {
+			addPkgWarning(fmt.Sprintf("fixed version %s is a prerelease; confirm the fix is in a stable release", vr.Fixed))
+		}
+	}
+}
+
+// initialVersion returns the smallest (non-prerelease) version
+// allowed by the major version suffix of modPath, if any.
+func initialVersion(modPath string) string {
+	_, pathMajor, ok := module.SplitPathVersion(modPath)
+	if !ok || pathMajor == "" {
+		return "0.0.0"
+	}
+	return strings.TrimLeft(pathMajor, "/.v") + ".0.0"
+}
+
+// checkIntroduced warns if the introduced version of m's first
+// version range is suspicious given m's released versions:
+//   - it is the first release of m, so it is probably redundant;
+//   - it is the last release before the fixed version, which often
+//     means that only the version named in a CVE was marked as
+//     affected. This warning is skipped if the report has notes,
+//     which are assumed to explain the choice of version.
+func (m *Module) checkIntroduced(pc *proxy.Client, hasNotes bool, addPkgWarning func(string)) {
+	if len(m.Versions) == 0 {
+		return
+	}
+	vr := m.Versions[0]
+	if vr.Introduced == "" || vr.Introduced == initialVersion(m.Module) {
+		return
+	}
+	released, err := pc.Versions(m.Module)
+	if err != nil || !slices.Contains(released, vr.Introduced) {
+		return
+	}
+	var earlier, between bool
+	for _, v := range released {
+		if version.Before(v, vr.Introduced) {
+			earlier = true
+		}
+		if vr.Fixed != "" && version.Before(vr.Introduced, v) && version.Before(v, vr.Fixed) {
+			between = true
+		}
+	}
+	switch {
+	case !earlier:
+		addPkgWarning(fmt.Sprintf("introduced version %s is the first release of %s; consider omitting it", vr.Introduced, m.Module))
+	case vr.Fixed != "" && !between && !hasNotes:
+		addPkgWarning(fmt.Sprintf("introduced version %s is the last release before fixed version %s; check whether earlier versions are affected, or add a note explaining why they are not", vr.Introduced, vr.Fixed))
+	}
+}
+
+func (m *Module) lintStdLib(addPkgIssue func(string), addPackageIssue func(int, string)) {
+	for j, p := range m.Packages {
 		if p.Package == "" {
-			addPkgIssue("missing package")
+			continue // reported by lintStructure
+		}
+		if !stdlib.Contains(p.Package) {
+			addPackageIssue(j, fmt.Sprintf("%q is not a standard library package; use its own module, not %q", p.Package, m.Module))
 		}
 	}
+	m.lintStdLibVersions(addPkgIssue)
 }
 
-func (m *Module) lintThirdParty(addPkgIssue func(string)) {
+// lintStdLibVersions checks that the versions of a standard library
+// module are Go release versions in semver form ("1.20.1"), rather
+// than Go toolchain versions ("go1.20.1") or module versions ("v1.20.1").
+// Versions that are invalid even without the prefix are reported by
+// lintVersions.
+func (m *Module) lintStdLibVersions(addPkgIssue func(string)) {
+	check := func(v string) {
+		if v == "" {
+			return
+		}
+		want := version.TrimPrefix(v)
+		if !version.IsValid(want) {
+			return
+		}
+		want = version.Canonical(want)
+		if want != v {
+			addPkgIssue(fmt.Sprintf("stdlib version should be %q not %q", want, v))
+		}
+	}
+	for _, vr := range m.Versions {
+		check(vr.Introduced)
+		check(vr.Fixed)
+	}
+	check(m.VulnerableAt)
+}
+
+func (m *Module) lintThirdParty(addPkgIssue func(string), addPackageIssue func(int, string)) {
 	if m.Module == "" {
-		addPkgIssue("missing module")
+		return // reported by lintStructure
+	}
+	if stdlib.Contains(m.Module) {
+		// The module path looks like a standard library import path
+		// (for example, "stdlib" or "net/http").
+		addPkgIssue(fmt.Sprintf("use module %q for standard library packages, not %q", stdlib.ModulePath, m.Module))
 		return
 	}
-	for _, p := range m.Packages {
+	for j, p := range m.Packages {
 		if p.Package == "" {
-			addPkgIssue("missing package")
-			continue
+			continue // reported by lintStructure
 		}
 		if !strings.HasPrefix(p.Package, m.Module) {
-			addPkgIssue("module must be a prefix of package")
+			addPackageIssue(j, "module must be a prefix of package")
+		}
+	}
+	for _, req := range m.VulnerableAtRequires {
+		// The module itself is already required at the vulnerable_at
+		// version, and a second requirement would conflict with it.
+		if path, _, _ := strings.Cut(req, "@"); path == m.Module {
+			addPkgIssue(fmt.Sprintf("vulnerable_at_requires must not contain the target module %s", m.Module))
+		}
+	}
+}
+
+// lintUnexportedSymbols warns if all of p's symbols are unexported
+// and no exported symbols have been derived from them, because then
+// the vulnerability can't be reached by importers of the package, and
+// the report is likely incomplete.
+//
+// Packages with a skip_fix reason are ignored, as are package main
+// targets, which have no importers (a "main" symbol, listed or derived,
+// is taken to mean that the package is a command).
+func (p *Package) lintUnexportedSymbols(addPkgWarning func(string)) {
+	if len(p.Symbols) == 0 || p.SkipFix != "" {
+		return
+	}
+	for _, syms := range [][]string{p.Symbols, p.DerivedSymbols} {
+		for _, s := range syms {
+			if s == "main" || isExportedSymbol(s) {
+				return
+			}
 		}
-		if err := module.CheckImportPath(p.Package); err != nil {
-			addPkgIssue(err.Error())
+	}
+	addPkgWarning(fmt.Sprintf("package %s: all listed symbols are unexported; consider listing exported entry points", p.Package))
+}
+
+// lintSymbols checks that p's symbols are not listed more than once,
+// and that no symbol is listed both as a function and as the type of
+// a method (as in "Foo" and "Foo.Bar"), which can't both exist.
+func (p *Package) lintSymbols(addPkgIssue func(string)) {
+	seen := make(map[string]bool)
+	types := make(map[string]bool)
+	for _, s := range p.Symbols {
+		if seen[s] {
+			addPkgIssue(fmt.Sprintf("duplicate symbol %q in package %s", s, p.Package))
+		}
+		seen[s] = true
+		if typ, _, ok := strings.Cut(s, "."); ok {
+			types[typ] = true
 		}
 	}
+	for _, s := range p.Symbols {
+		if !strings.Contains(s, ".") && types[s] {
+			addPkgIssue(fmt.Sprintf("symbol %q in package %s is listed as both a function and a type", s, p.Package))
+			// Report each name once.
+			delete(types, s)
+		}
+	}
+}
+
+// lintNoVersions warns if m lists no version ranges, so that all its
+// versions are treated as vulnerable, unless this is marked as intended
+// with all_versions (which can't be combined with versions).
+func (m *Module) lintNoVersions(addPkgIssue, addPkgWarning func(string)) {
+	switch {
+	case m.AllVersions && len(m.Versions) > 0:
+		addPkgIssue(fmt.Sprintf("module %s has both all_versions and versions set; remove one", m.Module))
+	case !m.AllVersions && len(m.Versions) == 0:
+		addPkgWarning(fmt.Sprintf("module %s has no version ranges; all versions will be considered affected — confirm this is intended (and set all_versions)", m.Module))
+	}
+}
+
+// lintNoSymbols warns if p lists no symbols, so that the whole package
+// is treated as vulnerable, unless this is marked as intended with
+// whole_package (which can't be combined with symbols).
+func (p *Package) lintNoSymbols(addPkgIssue, addPkgWarning func(string)) {
+	switch {
+	case p.WholePackage && len(p.Symbols) > 0:
+		addPkgIssue(fmt.Sprintf("package %s has both whole_package and symbols set; remove one", p.Package))
+	case !p.WholePackage && len(p.Symbols) == 0 && p.Package != "":
+		addPkgWarning(fmt.Sprintf("package %s has no symbols listed; the entire package will be treated as vulnerable — confirm this is intended (and set whole_package)", p.Package))
+	}
+}
+
+// isExportedSymbol reports whether the symbol s, of the form "Func" or
+// "Type.Method", can be called from outside its package. A method is
+// callable if its name is exported, even if the type is not, because
+// it may be called through an interface.
+func isExportedSymbol(s string) bool {
+	if i := strings.LastIndexByte(s, '.'); i >= 0 {
+		s = s[i+1:]
+	}
+	return token.IsExported(s)
+}
+
+// allSkipFix reports whether all of m's packages have a skip_fix reason.
+func (m *Module) allSkipFix() bool {
+	for _, p := range m.Packages {
+		if p.SkipFix == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// hasOnlyStdLibPackages reports whether m has at least one package,
+// and all of its packages are in the standard library.
+func (m *Module) hasOnlyStdLibPackages() bool {
+	if len(m.Packages) == 0 {
+		return false
+	}
+	for _, p := range m.Packages {
+		if !stdlib.Contains(p.Package) {
+			return false
+		}
+	}
+	return true
 }
 
 func (m *Module) lintVersions(addPkgIssue func(string)) {
@@ -111,50 +443,127 @@ func (m *Module) lintVersions(addPkgIssue func(string)) {
 	}
 }
 
-func (r *Report) lintCVEs(addIssue func(string)) {
-	for _, cve := range r.CVEs {
-		if !cveschema5.IsCVE(cve) {
-			addIssue("malformed cve identifier")
+// lintAdjacentRanges warns about consecutive version ranges of m
+// where the fixed version of one is the introduced version of the next.
+// Such ranges affect the same versions as a single merged range.
+func (m *Module) lintAdjacentRanges(addPkgWarning func(string)) {
+	format := func(vr VersionRange) string {
+		intro := vr.Introduced
+		if intro == "" {
+			intro = "0"
+		}
+		return fmt.Sprintf("[%s,%s)", intro, vr.Fixed)
+	}
+	for i := 1; i < len(m.Versions); i++ {
+		prev, current := m.Versions[i-1], m.Versions[i]
+		if prev.Fixed != "" && prev.Fixed == current.Introduced {
+			addPkgWarning(fmt.Sprintf("adjacent version ranges %s and %s could be merged", format(prev), format(current)))
 		}
 	}
+}
 
+// lintIDStructure checks that the report's identifiers are well-formed,
+// and that the required fields of cve_metadata are present.
+func (r *Report) lintIDStructure(addIssue func(field, msg string)) {
+	for i, cve := range r.CVEs {
+		if !cveschema5.IsCVE(cve) {
+			addIssue(fmt.Sprintf("cves[%d]", i), "malformed cve identifier")
+		}
+	}
 	if r.CVEMetadata != nil {
 		if r.CVEMetadata.ID == "" {
-			addIssue("cve_metadata.id is required")
+			addIssue("cve_metadata.id", "cve_metadata.id is required")
 		} else if !cveschema5.IsCVE(r.CVEMetadata.ID) {
-			addIssue("malformed cve_metadata.id identifier")
+			addIssue("cve_metadata.id", "malformed cve_metadata.id identifier")
 		}
 		if r.CVEMetadata.CWE == "" {
-			addIssue("cve_metadata.cwe is required")
+			addIssue("cve_metadata.cwe", "cve_metadata.cwe is required")
+		}
+	}
+	for i, g := range r.GHSAs {
+		if !ghsa.IsGHSA(g) {
+			addIssue(fmt.Sprintf("ghsas[%d]", i), fmt.Sprintf("%s is not a valid GHSA", g))
 		}
-		if strings.Contains(r.CVEMetadata.CWE, "TODO") {
-			addIssue("cve_metadata.cwe contains a TODO")
+	}
+	for i, related := range r.Related {
+		if !isIdentifier(related) {
+			addIssue(fmt.Sprintf("related[%d]", i), fmt.Sprintf("related: %s is not a recognized identifier (CVE, GHSA or Go ID)", related))
 		}
 	}
 }
 
-func (r *Report) lintGHSAs(addIssue func(string)) {
-	for _, g := range r.GHSAs {
-		if !ghsa.IsGHSA(g) {
-			addIssue(fmt.Sprintf("%s is not a valid GHSA", g))
+func (r *Report) lintCVEs(addIssue func(field, msg string)) {
+	if r.CVEMetadata == nil {
+		return
+	}
+	if strings.Contains(r.CVEMetadata.CWE, "TODO") {
+		addIssue("cve_metadata.cwe", "cve_metadata.cwe contains a TODO")
+	}
+	// An advisory for a different CVE means that either the
+	// advisory or the self-assigned CVE is wrong.
+	if id := r.CVEMetadata.ID; id != "" {
+		for _, ref := range r.ReferencesByType(osv.ReferenceTypeAdvisory) {
+			for _, re := range []*regexp.Regexp{nistRegex, mitreRegex} {
+				if m := re.FindStringSubmatch(ref.URL); len(m) > 0 && m[1] != id {
+					addIssue(r.referenceField(ref), fmt.Sprintf("cve_metadata.id %s conflicts with advisory %s", id, m[1]))
+				}
+			}
 		}
 	}
 }
 
-func (r *Report) lintRelated(addIssue func(string)) {
+var (
+	// cweIDRegex matches CWE IDs, including those written with a
+	// space instead of a hyphen.
+	cweIDRegex = regexp.MustCompile(`CWE[- ]\d+`)
+	// cweRegex matches a CWE in the canonical form "CWE-N: Title".
+	cweRegex = regexp.MustCompile(`^CWE-\d+: \S`)
+)
+
+// lintCWE checks that cve_metadata.cwe is a single CWE, in the form
+// "CWE-N: Title". The CVE record has a single problem type, so the
+// field can't hold a list.
+func (r *Report) lintCWE(addIssue, addWarning func(string)) {
+	if r.CVEMetadata == nil {
+		return
+	}
+	cwe := r.CVEMetadata.CWE
+	if cwe == "" || strings.Contains(cwe, "TODO") {
+		return // reported elsewhere
+	}
+	if n := len(cweIDRegex.FindAllString(cwe, -1)); n != 1 {
+		addIssue(fmt.Sprintf("cve_metadata.cwe should contain a single CWE; found %d", n))
+		return
+	}
+	if !cweRegex.MatchString(cwe) {
+		addWarning(fmt.Sprintf("cve_metadata.cwe %q should have the form \"CWE-N: Title\"", cwe))
+	}
+}
+
+// lintCVEDescription checks that a report with a CVE assigned by the
+// Go CNA has a CVE description, which the CNA process requires. It is
+// only a warning because ToCVE5 falls back to the top-level
+// description, which many older reports rely on.
+func (r *Report) lintCVEDescription(addWarning func(string)) {
+	if r.CVEMetadata == nil || r.CVEMetadata.ID == "" {
+		return
+	}
+	if r.CVEMetadata.Description == "" {
+		addWarning("cve_metadata.description is required for self-assigned CVEs")
+	}
+}
+
+func (r *Report) lintRelated(addIssue func(field, msg string)) {
 	if len(r.Related) == 0 {
 		return
 	}
 
 	aliases := r.Aliases()
-	for _, related := range r.Related {
+	for i, related := range r.Related {
 		// In most cases, the related list is very short, so there's no
 		// need create a map of aliases.
 		if slices.Contains(aliases, related) {
-			addIssue(fmt.Sprintf("related: identifier %s is also listed among aliases", related))
-		}
-		if !isIdentifier(related) {
-			addIssue(fmt.Sprintf("related: %s is not a recognized identifier (CVE, GHSA or Go ID)", related))
+			addIssue(fmt.Sprintf("related[%d]", i), fmt.Sprintf("related: identifier %s is also listed among aliases", related))
 		}
 	}
 }
@@ -171,19 +580,60 @@ func IsGoID(s string) bool {
 
 const maxLineLength = 80
 
-func (r *Report) lintLineLength(field, content string, addIssue func(string)) {
-	for _, line := range strings.Split(content, "\n") {
-		if len(line) <= maxLineLength {
+// defaultMaxLineLengths are the default maximum line lengths
+// of the fields checked by lintLineLength.
+var defaultMaxLineLengths = map[string]int{
+	"description":              maxLineLength,
+	"cve_metadata.description": maxLineLength,
+}
+
+// maxLineLength returns the maximum line length for field.
+func (cfg *LintConfig) maxLineLength(field string) int {
+	if n, ok := cfg.MaxLineLengths[field]; ok && n > 0 {
+		return n
+	}
+	return defaultMaxLineLengths[field]
+}
+
+func (r *Report) lintLineLength(field, content string, max int, addIssue func(string)) {
+	// Carriage returns are reported by lintWhitespace.
+	for _, line := range strings.Split(normalizeNewlines(content), "\n") {
+		if len(line) <= max {
 			continue
 		}
 		if !strings.Contains(line, " ") {
 			continue // A single long word is OK.
 		}
-		addIssue(fmt.Sprintf("%v contains line > %v characters long: %q", field, maxLineLength, line))
+		addIssue(fmt.Sprintf("%v contains line > %v characters long: %q", field, max, line))
 		return
 	}
 }
 
+// lintWhitespace checks that no line of content has trailing whitespace,
+// that content has no tabs other than in leading indentation
+// or trailing whitespace, and that it has no carriage returns
+// (as in Windows line endings).
+func lintWhitespace(field, content string, addIssue func(string)) {
+	if strings.Contains(content, "\r") {
+		addIssue(fmt.Sprintf("%s contains a carriage return; use \\n line endings", field))
+	}
+	trailing, tab := false, false
+	for _, line := range strings.Split(content, "\n") {
+		if strings.TrimRight(line, " \t") != line {
+			trailing = true
+		}
+		if strings.Contains(strings.Trim(line, " \t"), "\t") {
+			tab = true
+		}
+	}
+	if trailing {
+		addIssue(fmt.Sprintf("%s contains trailing whitespace", field))
+	}
+	if tab {
+		addIssue(fmt.Sprintf("%s contains a tab character", field))
+	}
+}
+
 // Regex patterns for standard links.
 var (
 	prRegex       = regexp.MustCompile(`https://go.dev/cl/\d+`)
@@ -196,62 +646,358 @@ var (
 	mitreRegex    = regexp.MustCompile(`^https://cve.mitre.org/.*(` + cveschema5.Regex + `)$`)
 )
 
+// forges are the code hosting sites for which the repository of a
+// module or reference can be derived from the first two path elements
+// after the host (e.g. github.com/owner/repo).
+var forges = []string{"github.com", "gitlab.com", "bitbucket.org"}
+
+// forgeRepo returns the repository (of the form "host/owner/repo")
+// contained in path, or "" if path does not start with a known forge.
+func forgeRepo(path string) string {
+	parts := strings.Split(path, "/")
+	if len(parts) < 3 || !slices.Contains(forges, parts[0]) {
+		return ""
+	}
+	if parts[1] == "" || parts[2] == "" {
+		return ""
+	}
+	return strings.ToLower(strings.Join(parts[:3], "/"))
+}
+
+// lintPathCasing warns if the owner or repository of m's module path on
+// a known forge contains upper-case letters. Forges treat these names
+// case-insensitively, but Go import paths are case-sensitive, and most
+// module paths on forges are lower-case, so mixed case is often a
+// mistake (as in github.com/Sirupsen/logrus, which should be
+// github.com/sirupsen/logrus).
+//
+// Because some modules do use mixed case, the warning is omitted if a
+// reference links to the same repository with exactly the same casing.
+// Excluded reports are not checked: they usually have no references
+// to confirm the casing, and their modules are not published.
+func (m *Module) lintPathCasing(refs []*Reference, addPkgWarning func(string)) {
+	if forgeRepo(m.Module) == "" {
+		return
+	}
+	// forgeRepo lower-cases the repo, so take the original casing
+	// from the module path.
+	repo := strings.Join(strings.Split(m.Module, "/")[:3], "/")
+	if repo == strings.ToLower(repo) {
+		return
+	}
+	for _, ref := range refs {
+		u, err := url.Parse(ref.URL)
+		if err != nil {
+			continue
+		}
+		if p := u.Host + u.Path; p == repo || strings.HasPrefix(p, repo+"/") {
+			return
+		}
+	}
+	addPkgWarning(fmt.Sprintf("module path casing may be wrong: %s", m.Module))
+}
+
+// lintFixHosts checks that FIX references to a known forge point at
+// the repository of one of the report's modules. References to a fork
+// (for example, a pull request opened from another user's copy of the
+// repository) are flagged for reviewer attention.
+func (r *Report) lintFixHosts(addWarning func(field, msg string)) {
+	var modRepos []string
+	for _, m := range r.Modules {
+		if repo := forgeRepo(m.Module); repo != "" && !slices.Contains(modRepos, repo) {
+			modRepos = append(modRepos, repo)
+		}
+	}
+	if len(modRepos) == 0 {
+		// The module host can't be derived, so there is nothing to
+		// compare against.
+		return
+	}
+	for _, ref := range r.ReferencesByType(osv.ReferenceTypeFix) {
+		u, err := url.Parse(ref.URL)
+		if err != nil {
+			continue
+		}
+		refRepo := forgeRepo(u.Host + u.Path)
+		if refRepo == "" || slices.Contains(modRepos, refRepo) {
+			continue
+		}
+		addWarning(r.referenceField(ref), fmt.Sprintf("fix reference host %s doesn't match module host %s", refRepo, strings.Join(modRepos, ", ")))
+	}
+}
+
+// checkReferenceRepos checks that the GitHub repositories linked from
+// r's references still exist, using c. Deleted repositories, and
+// repositories that have been made private, are reported as
+// unavailable. Transient failures (errors making the request, or
+// statuses other than success or not found) are not reported, because
+// they don't show that the reference is broken.
+func (r *Report) checkReferenceRepos(c *URLCache, addWarning func(field, msg string)) {
+	var repos []string
+	// The first reference to each repo, which issues are reported on.
+	refs := make(map[string]*Reference)
+	for _, ref := range r.References {
+		u, err := url.Parse(ref.URL)
+		if err != nil {
+			continue
+		}
+		repo := forgeRepo(u.Host + u.Path)
+		if !strings.HasPrefix(repo, "github.com/") || strings.HasPrefix(repo, "github.com/advisories/") {
+			continue
+		}
+		if !slices.Contains(repos, repo) {
+			repos = append(repos, repo)
+			refs[repo] = ref
+		}
+	}
+	for _, repo := range repos {
+		s, err := c.status("https://" + repo)
+		if err != nil || !isDefinitive(s) {
+			continue
+		}
+		if s == http.StatusNotFound || s == http.StatusGone {
+			addWarning(r.referenceField(refs[repo]), fmt.Sprintf("reference repo %s is unavailable (%d)", repo, s))
+		}
+	}
+}
+
+// fixCommitRegex matches links to commits on go.googlesource.com,
+// GitHub and GitLab, capturing the commit hash.
+var fixCommitRegex = regexp.MustCompile(`^https://(?:go\.googlesource\.com/[^/]+/\+(?:/commit)?|(?:github\.com|gitlab\.com)/[^/]+/[^/]+(?:/-)?/commit)/([0-9a-f]+)(?:[?#]|$)`)
+
+// lintFixCommits checks that FIX references to commits use the full
+// commit hash, because abbreviated hashes can become ambiguous as a
+// repository grows.
+func (r *Report) lintFixCommits(addWarning func(field, msg string)) {
+	for _, ref := range r.ReferencesByType(osv.ReferenceTypeFix) {
+		if m := fixCommitRegex.FindStringSubmatch(ref.URL); m != nil && len(m[1]) < 40 {
+			addWarning(r.referenceField(ref), fmt.Sprintf("%q: fix reference uses abbreviated commit hash; use the full 40-character SHA", ref.URL))
+		}
+	}
+}
+
+var (
+	// githubRefRegex matches links to files, trees and commit lists
+	// at a ref of a GitHub repo, capturing the ref.
+	githubRefRegex = regexp.MustCompile(`^https://github\.com/[^/]+/[^/]+/(?:blob|tree|commits)/([^/?#]+)`)
+	// googlesourceRefRegex matches links to refs of a googlesource.com
+	// repo, capturing the ref and the rest of the path.
+	googlesourceRefRegex = regexp.MustCompile(`^https://[^/]+\.googlesource\.com/.+?/\+/([^?#]+)`)
+	// tagLikeRegex matches refs that look like version tags,
+	// such as "v1.2.3" and "go1.21.0".
+	tagLikeRegex = regexp.MustCompile(`^(?:v|go)?\d+(?:\.\d+)*`)
+)
+
+// lintFixBranches checks that FIX references don't point to branches,
+// which move over time, rather than to commits.
+func (r *Report) lintFixBranches(addIssue func(field, msg string)) {
+	for _, ref := range r.ReferencesByType(osv.ReferenceTypeFix) {
+		if isBranchLink(ref.URL) {
+			addIssue(r.referenceField(ref), fmt.Sprintf("%q: fix reference points to a branch, not a specific commit", ref.URL))
+		}
+	}
+}
+
+// isBranchLink reports whether u is a link to a branch of a GitHub or
+// googlesource.com repo, or to a file or tree at a branch. Links to
+// commit hashes and to refs that look like version tags are not, and
+// neither are links to Gerrit code reviews.
+func isBranchLink(u string) bool {
+	var ref string
+	if m := githubRefRegex.FindStringSubmatch(u); m != nil {
+		ref = m[1]
+	} else if m := googlesourceRefRegex.FindStringSubmatch(u); m != nil && !strings.Contains(u, "-review.googlesource.com/") {
+		switch path := strings.TrimPrefix(m[1], "commit/"); {
+		case strings.HasPrefix(path, "refs/heads/"):
+			return true
+		case strings.HasPrefix(path, "refs/tags/"):
+			return false
+		default:
+			ref, _, _ = strings.Cut(path, "/")
+		}
+	} else {
+		return false
+	}
+	return !version.IsCommitHash(ref) && !tagLikeRegex.MatchString(ref)
+}
+
+// lintFragments warns about pairs of references that differ only by
+// the fragment of their URLs, where one of them has no fragment, as in
+// "https://example.com/advisory" and "https://example.com/advisory#a".
+// These usually indicate a copy mistake.
+//
+// References whose URLs have different non-empty fragments are allowed,
+// since those can point to the sections of a single page that describe
+// different vulnerabilities, for example the entries of a changelog.
+func (r *Report) lintFragments(addWarning func(field, msg string)) {
+	bare := make(map[string]bool) // URLs without fragments
+	for _, ref := range r.References {
+		if !strings.Contains(ref.URL, "#") {
+			bare[ref.URL] = true
+		}
+	}
+	for _, ref := range r.References {
+		base, _, _ := strings.Cut(ref.URL, "#")
+		if base != ref.URL && bare[base] {
+			addWarning(r.referenceField(ref), fmt.Sprintf("%q and %q: references differ only by fragment; likely duplicate", ref.URL, base))
+		}
+	}
+}
+
 // Checks that the "links" section of a Report for a package in the
 // standard library contains all necessary links, and no third-party links.
-func (r *Report) lintStdLibLinks(addIssue func(string)) {
+func (r *Report) lintStdLibLinks(addIssue func(field, msg string), addWarning func(string)) {
+	for _, ref := range r.ReferencesByType(osv.ReferenceTypeAdvisory) {
+		addIssue(r.referenceField(ref), fmt.Sprintf("%q: advisory reference should not be set for first-party issues", ref.URL))
+	}
+
+	fixes := r.ReferencesByType(osv.ReferenceTypeFix)
+	isToolchain := slices.ContainsFunc(r.Modules, func(m *Module) bool {
+		return stdlib.IsCmdModule(m.Module)
+	})
+	for _, ref := range fixes {
+		field := r.referenceField(ref)
+		switch {
+		case prRegex.MatchString(ref.URL) || commitRegex.MatchString(ref.URL):
+		case gerritCLRegex.MatchString(ref.URL):
+			// Reported (and fixed) as an unfixed link.
+		case strings.Contains(ref.URL, "go-review.googlesource.com"):
+			addIssue(field, fmt.Sprintf("%q: fix reference should link to the CL itself, in the form https://go.dev/cl/NUMBER", ref.URL))
+		default:
+			addIssue(field, fmt.Sprintf("%q: fix reference should match %q or %q", ref.URL, prRegex, commitRegex))
+		}
+		// Toolchain fixes are made in the go repo. (The repo of a
+		// CL can't be determined from its link, so only commit
+		// links are checked.)
+		if isToolchain && commitRegex.MatchString(ref.URL) &&
+			!strings.HasPrefix(ref.URL, "https://go.googlesource.com/go/+/") {
+			addIssue(field, fmt.Sprintf("%q: toolchain fix reference should be in the go repository", ref.URL))
+		}
+	}
+
 	var (
-		hasFixLink      = false
 		hasReportLink   = false
 		hasAnnounceLink = false
+		// Security releases are announced on golang-announce;
+		// links to golang-dev and golang-nuts are supplementary.
+		hasGolangAnnounceLink = false
+
+		announceURLs []string
 	)
 	for _, ref := range r.References {
+		field := r.referenceField(ref)
 		switch ref.Type {
-		case osv.ReferenceTypeAdvisory:
-			addIssue(fmt.Sprintf("%q: advisory reference should not be set for first-party issues", ref.URL))
-		case osv.ReferenceTypeFix:
-			hasFixLink = true
-			if !prRegex.MatchString(ref.URL) && !commitRegex.MatchString(ref.URL) {
-				addIssue(fmt.Sprintf("%q: fix reference should match %q or %q", ref.URL, prRegex, commitRegex))
-			}
 		case osv.ReferenceTypeReport:
 			hasReportLink = true
 			if !issueRegex.MatchString(ref.URL) {
-				addIssue(fmt.Sprintf("%q: report reference should match %q", ref.URL, issueRegex))
+				addIssue(field, fmt.Sprintf("%q: report reference should match %q", ref.URL, issueRegex))
 			}
 		case osv.ReferenceTypeWeb:
 			if !announceRegex.MatchString(ref.URL) {
-				addIssue(fmt.Sprintf("%q: web references should only contain announcement links matching %q", ref.URL, announceRegex))
+				addIssue(field, fmt.Sprintf("%q: web references should only contain announcement links matching %q", ref.URL, announceRegex))
 			} else {
 				hasAnnounceLink = true
+				if announceRegex.FindStringSubmatch(ref.URL)[1] == "announce" {
+					hasGolangAnnounceLink = true
+				}
+				if slices.Contains(announceURLs, ref.URL) {
+					addIssue(field, fmt.Sprintf("%q: duplicate announcement link", ref.URL))
+				} else {
+					announceURLs = append(announceURLs, ref.URL)
+				}
 			}
 		}
 	}
-	if !hasFixLink {
-		addIssue("references should contain at least one fix")
+	if len(fixes) == 0 {
+		addIssue("", "references should contain at least one fix")
 	}
 	if !hasReportLink {
-		addIssue("references should contain at least one report")
+		addIssue("", "references should contain at least one report")
 	}
 	if !hasAnnounceLink {
-		addIssue(fmt.Sprintf("references should contain an announcement link matching %q", announceRegex))
+		addIssue("", fmt.Sprintf("references should contain an announcement link matching %q", announceRegex))
+	} else if !hasGolangAnnounceLink {
+		addWarning("references should contain a golang-announce link for the security release")
 	}
 }
 
-func (r *Report) lintLinks(addIssue func(string)) {
-	advisoryCount := 0
+// lintMirrorModules checks the third-party modules of a report that
+// also lists the standard library or toolchain. Such modules must be
+// golang.org/x modules that mirror the vulnerable first-party code,
+// and must be fixed if and only if the first-party modules are.
+func (r *Report) lintMirrorModules(addIssue func(field, msg string)) {
+	isFixed := func(m *Module) bool {
+		n := len(m.Versions)
+		return n > 0 && m.Versions[n-1].Fixed != ""
+	}
+	firstPartyFixed := false
+	for _, m := range r.Modules {
+		if m.IsFirstParty() && isFixed(m) {
+			firstPartyFixed = true
+		}
+	}
+	for i, m := range r.Modules {
+		if m.IsFirstParty() || m.Module == "" {
+			continue
+		}
+		field := moduleField(i)
+		if !strings.HasPrefix(m.Module, "golang.org/x/") {
+			addIssue(field, fmt.Sprintf("module %s can't be listed with the standard library; only golang.org/x modules can mirror it", m.Module))
+			continue
+		}
+		switch fixed := isFixed(m); {
+		case firstPartyFixed && !fixed:
+			addIssue(field, fmt.Sprintf("module %s is not fixed, but the standard library is; versions of mirror modules should align", m.Module))
+		case !firstPartyFixed && fixed:
+			addIssue(field, fmt.Sprintf("module %s is fixed, but the standard library is not; versions of mirror modules should align", m.Module))
+		}
+	}
+}
+
+// vulnDBPrefixes are the prefixes (host and path) of URLs that point
+// into the Go vulnerability database: its website, its OSV endpoints
+// and its source repository.
+var vulnDBPrefixes = []string{
+	"pkg.go.dev/vuln/",
+	"vuln.go.dev/",
+	"github.com/golang/vulndb/",
+	"go.googlesource.com/vulndb/",
+}
+
+// lintSelfReferences checks that no reference points to r itself in the
+// Go vulnerability database, which would be circular. References to
+// other reports (for example, related vulnerabilities) are allowed.
+func (r *Report) lintSelfReferences(addIssue func(field, msg string)) {
+	if r.ID == "" {
+		return
+	}
 	for _, ref := range r.References {
-		if !slices.Contains(osv.ReferenceTypes, ref.Type) {
-			addIssue(fmt.Sprintf("%q is not a valid reference type", ref.Type))
+		u, err := url.Parse(ref.URL)
+		if err != nil {
+			continue
 		}
-		l := ref.URL
-		if _, err := url.ParseRequestURI(l); err != nil {
-			addIssue(fmt.Sprintf("%q is not a valid URL", l))
+		path := strings.ToLower(u.Host) + u.Path
+		if !slices.ContainsFunc(vulnDBPrefixes, func(p string) bool { return strings.HasPrefix(path, p) }) {
+			continue
+		}
+		for _, elem := range strings.Split(u.Path, "/") {
+			if strings.TrimSuffix(elem, filepath.Ext(elem)) == r.ID {
+				addIssue(r.referenceField(ref), fmt.Sprintf("%q: reference points back to the Go vuln database; remove it", ref.URL))
+				break
+			}
 		}
+	}
+}
+
+func (r *Report) lintLinks(addIssue func(field, msg string)) {
+	for i, ref := range r.References {
+		field := fmt.Sprintf("references[%d]", i)
+		l := ref.URL
 		if fixed := fixURL(l); fixed != l {
-			addIssue(fmt.Sprintf("unfixed url: %q should be %q", l, fixURL(l)))
+			addIssue(field, fmt.Sprintf("unfixed url: %q should be %q", l, fixURL(l)))
 		}
-		if ref.Type == osv.ReferenceTypeAdvisory {
-			advisoryCount++
+		if stripTrackingParams(l) != l {
+			addIssue(field, fmt.Sprintf("%q: reference URL contains tracking parameters", l))
 		}
 		if ref.Type != osv.ReferenceTypeAdvisory {
 			// An ADVISORY reference to a CVE/GHSA indicates that it
@@ -262,35 +1008,276 @@ func (r *Report) lintLinks(addIssue func(string)) {
 			//
 			// A reference to a CVE/GHSA that appears in the CVEs/GHSAs
 			// aliases is redundant.
-			for _, re := range []*regexp.Regexp{nistRegex, mitreRegex, ghsaLinkRegex} {
-				if m := re.FindStringSubmatch(ref.URL); len(m) > 0 {
-					id := m[1]
-					if slices.Contains(r.CVEs, id) || slices.Contains(r.GHSAs, id) {
-						addIssue(fmt.Sprintf("redundant non-advisory reference to %v", id))
-					}
+			if id := linkedID(ref.URL); id != "" {
+				if slices.Contains(r.CVEs, id) || slices.Contains(r.GHSAs, id) {
+					addIssue(field, fmt.Sprintf("redundant non-advisory reference to %v", id))
 				}
 			}
 		}
 	}
+
+	r.lintReferenceTypes(addIssue)
+
+	advisoryCount := 0
+	// Number of advisory references for each CVE/GHSA.
+	advisoryIDs := make(map[string]int)
+	for _, ref := range r.ReferencesByType(osv.ReferenceTypeAdvisory) {
+		if id := linkedID(ref.URL); id != "" {
+			advisoryIDs[id]++
+			if advisoryIDs[id] > 1 {
+				continue // reported below
+			}
+		}
+		advisoryCount++
+	}
+	ids := maps.Keys(advisoryIDs)
+	slices.Sort(ids)
+	for _, id := range ids {
+		if advisoryIDs[id] > 1 {
+			addIssue("", fmt.Sprintf("multiple advisory references for the same %s", id))
+		}
+	}
 	if advisoryCount > 1 {
-		addIssue("references should contain at most one advisory link")
+		addIssue("", "references should contain at most one advisory link")
+	}
+}
+
+// lintReferenceTypes checks that no URL is listed under more than one
+// reference type, comparing URLs as normalized by Fix. Issues are
+// reported on the first reference to the URL.
+func (r *Report) lintReferenceTypes(addIssue func(field, msg string)) {
+	types := make(map[string][]string)
+	first := make(map[string]*Reference)
+	for _, ref := range r.References {
+		u := normalizeURL(ref.URL)
+		if first[u] == nil {
+			first[u] = ref
+		}
+		if t := string(ref.Type); !slices.Contains(types[u], t) {
+			types[u] = append(types[u], t)
+		}
+	}
+	urls := maps.Keys(types)
+	slices.Sort(urls)
+	for _, u := range urls {
+		ts := types[u]
+		if len(ts) < 2 {
+			continue
+		}
+		slices.Sort(ts)
+		list := strings.Join(ts[:len(ts)-1], ", ") + " and " + ts[len(ts)-1]
+		if len(ts) == 2 {
+			list = "both " + list
+		}
+		addIssue(r.referenceField(first[u]), fmt.Sprintf("url %s is listed as %s", u, list))
 	}
 }
 
-func (r *Report) lintDescription(addIssue func(string)) {
+// lintOSVReferences checks that each of r's references appears
+// unchanged in r's OSV entry, after a round trip through JSON
+// (which, for example, replaces invalid UTF-8).
+func (r *Report) lintOSVReferences(addIssue func(field, msg string)) {
+	b, err := json.Marshal(r.ToOSV(time.Time{}))
+	if err != nil {
+		addIssue("", fmt.Sprintf("could not convert to OSV: %v", err))
+		return
+	}
+	var entry osv.Entry
+	if err := json.Unmarshal(b, &entry); err != nil {
+		addIssue("", fmt.Sprintf("could not convert to OSV: %v", err))
+		return
+	}
+	for _, ref := range r.References {
+		if !slices.Contains(osv.ReferenceTypes, ref.Type) ||
+			!slices.Contains(entry.References, osv.Reference(*ref)) {
+			addIssue(r.referenceField(ref), fmt.Sprintf("reference would be dropped in OSV conversion: %s", ref.URL))
+		}
+	}
+}
+
+// linkedID returns the CVE or GHSA that the given NIST, MITRE or
+// GitHub link refers to, or "" if it is not such a link.
+func linkedID(link string) string {
+	for _, re := range []*regexp.Regexp{nistRegex, mitreRegex, ghsaLinkRegex} {
+		if m := re.FindStringSubmatch(link); len(m) > 0 {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// canonicalGHSALink returns the canonical form of the GitHub link
+// to the global advisory for the GHSA that ref is an ADVISORY link to,
+// or "" if ref is not such a link or is already canonical.
+//
+// Repository advisories, at github.com/owner/repo/security/advisories,
+// are only assumed to have a global advisory if r lists their GHSA,
+// because GHSAs are only listed if they are in the global database.
+func (r *Report) canonicalGHSALink(ref *Reference) string {
+	if ref.Type != osv.ReferenceTypeAdvisory {
+		return ""
+	}
+	m := ghsaLinkRegex.FindStringSubmatch(ref.URL)
+	if len(m) == 0 || !slices.Contains(r.GHSAs, m[1]) {
+		return ""
+	}
+	if c := "https://github.com/advisories/" + m[1]; c != ref.URL {
+		return c
+	}
+	return ""
+}
+
+// lintGHSALinks warns about ADVISORY links to GHSAs that don't use
+// the canonical form of the URL (see canonicalGHSALink).
+func (r *Report) lintGHSALinks(addWarning func(field, msg string)) {
+	for _, ref := range r.References {
+		if c := r.canonicalGHSALink(ref); c != "" {
+			addWarning(r.referenceField(ref), fmt.Sprintf("%q: non-canonical GHSA advisory URL; use %q", ref.URL, c))
+		}
+	}
+}
+
+// cveAggregators are the hosts of sites that republish CVE records,
+// which should be linked to at NVD instead.
+var cveAggregators = []string{
+	"cvedetails.com",
+	"cvefeed.io",
+	"opencve.io",
+	"vulmon.com",
+}
+
+// cveRegex matches CVE IDs anywhere in a string.
+var cveRegex = regexp.MustCompile(cveschema5.Regex)
+
+// aggregatorNVDLink reports whether the URL u is a link to a single
+// CVE on one of the cveAggregators. If so, it returns the aggregator's
+// host (as listed in cveAggregators) and the NVD URL for the CVE.
+func aggregatorNVDLink(u string) (host, nvd string, ok bool) {
+	pu, err := url.Parse(u)
+	if err != nil {
+		return "", "", false
+	}
+	h := strings.ToLower(pu.Hostname())
+	for _, a := range cveAggregators {
+		if h != a && !strings.HasSuffix(h, "."+a) {
+			continue
+		}
+		cves := slices.Compact(cveRegex.FindAllString(u, -1))
+		if len(cves) != 1 {
+			return "", "", false
+		}
+		return a, "https://nvd.nist.gov/vuln/detail/" + cves[0], true
+	}
+	return "", "", false
+}
+
+// lintCVEAggregators warns about references to CVEs on aggregator
+// sites, rather than on NVD, the authoritative source.
+func (r *Report) lintCVEAggregators(addWarning func(field, msg string)) {
+	for _, ref := range r.References {
+		if host, nvd, ok := aggregatorNVDLink(ref.URL); ok {
+			addWarning(r.referenceField(ref), fmt.Sprintf("%q: reference uses CVE aggregator %s; prefer NVD (%s)", ref.URL, host, nvd))
+		}
+	}
+}
+
+// ghsaRegex matches GHSA IDs anywhere in a string.
+var ghsaRegex = regexp.MustCompile(ghsa.Regex)
+
+// lintSummaryIDs checks that the summary doesn't contain a CVE or GHSA
+// ID. Summaries are shown in the public listing, where the IDs are
+// already displayed, so they should describe the vulnerability instead.
+func (r *Report) lintSummaryIDs(addWarning func(string)) {
+	if cveRegex.MatchString(r.Summary) || ghsaRegex.MatchString(r.Summary) {
+		addWarning("summary should describe the vulnerability, not restate the identifier")
+	}
+}
+
+// lintExternalIDs checks that the report has some link to an external
+// source of information: a CVE, a GHSA or an advisory reference.
+func (r *Report) lintExternalIDs(addWarning func(string)) {
+	if len(r.Aliases()) > 0 {
+		return
+	}
+	if r.AdvisoryReference() != nil {
+		return
+	}
+	addWarning("report has no CVE, GHSA, or advisory reference")
+}
+
+func (r *Report) lintDescription(addIssue func(field, msg string)) {
 	if r.Description == "" && r.CVEMetadata != nil {
-		addIssue("missing description (reports with Go CVEs must have a description)")
+		addIssue("description", "missing description (reports with Go CVEs must have a description)")
 	}
-	hasAdvisory := func() bool {
-		for _, ref := range r.References {
-			if ref.Type == osv.ReferenceTypeAdvisory {
+	if r.Description == "" && r.CVEMetadata == nil && r.AdvisoryReference() == nil {
+		addIssue("", "missing advisory (reports without descriptions must have an advisory link)")
+	}
+}
+
+// githubPathRegex matches import paths hosted on GitHub.
+var githubPathRegex = regexp.MustCompile(`github\.com/[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+(?:/[A-Za-z0-9_.~-]+)*`)
+
+// lintDescriptionModules warns about GitHub import paths in r's
+// description that are not related to any of r's modules or packages,
+// which often indicate that the description was copied from another
+// report. A path is related to a module or package if either is a
+// prefix of the other, ignoring case. URLs are not checked.
+//
+// This is only a heuristic: descriptions may legitimately mention
+// other projects, so it's a warning rather than an error.
+func (r *Report) lintDescriptionModules(addWarning func(string)) {
+	var affected []string
+	for _, m := range r.Modules {
+		affected = append(affected, strings.ToLower(m.Module))
+		for _, p := range m.Packages {
+			affected = append(affected, strings.ToLower(p.Package))
+		}
+	}
+	related := func(path string) bool {
+		path = strings.ToLower(path)
+		for _, a := range affected {
+			if a != "" && (isPathPrefix(a, path) || isPathPrefix(path, a)) {
 				return true
 			}
 		}
 		return false
 	}
-	if r.Description == "" && r.CVEMetadata == nil && !hasAdvisory() {
-		addIssue("missing advisory (reports without descriptions must have an advisory link)")
+	seen := make(map[string]bool)
+	for _, loc := range githubPathRegex.FindAllStringIndex(r.Description, -1) {
+		if strings.HasSuffix(r.Description[:loc[0]], "//") {
+			// Part of a URL, such as a link to an issue.
+			continue
+		}
+		// Drop punctuation that ends a sentence.
+		path := strings.TrimRight(r.Description[loc[0]:loc[1]], ".")
+		if seen[path] || related(path) {
+			continue
+		}
+		seen[path] = true
+		addWarning(fmt.Sprintf("description references %s which is not an affected module", path))
+	}
+}
+
+// isPathPrefix reports whether prefix is equal to path, or is a
+// prefix of path made up of whole path elements.
+func isPathPrefix(prefix, path string) bool {
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// advisoryURLRegex matches the URLs of Go advisories.
+var advisoryURLRegex = regexp.MustCompile(`^` + regexp.QuoteMeta(goURLPrefix) + `GO-\d{4}-\d{4,}$`)
+
+// lintDatabaseSpecific checks the URL that will appear in the
+// database_specific field of r's OSV entry.
+//
+// The URL is only required to be valid once the report has been
+// published, because new reports may have placeholder IDs.
+func (r *Report) lintDatabaseSpecific(addIssue func(string)) {
+	if r.Published.IsZero() {
+		return
+	}
+	if u := r.osvDatabaseSpecific().URL; !advisoryURLRegex.MatchString(u) {
+		addIssue(fmt.Sprintf("database_specific.url %q is not a valid Go advisory URL", u))
 	}
 }
 
@@ -303,37 +1290,196 @@ var (
 	errWrongID  = errors.New("report ID mismatch")
 )
 
+// A ReportKind is the kind of a report, which determines the
+// directory of the vulndb repo that it belongs in.
+type ReportKind int
+
+const (
+	// KindReport is the kind of a report of a vulnerability,
+	// which belongs in data/reports.
+	KindReport ReportKind = iota
+	// KindExcluded is the kind of an excluded report,
+	// which belongs in data/excluded.
+	KindExcluded
+)
+
+// String returns the name of the directory for reports of kind k.
+func (k ReportKind) String() string {
+	switch k {
+	case KindReport:
+		return "reports"
+	case KindExcluded:
+		return "excluded"
+	default:
+		return fmt.Sprintf("ReportKind(%d)", int(k))
+	}
+}
+
+// Kind returns the kind of r, based on its contents.
+func (r *Report) Kind() ReportKind {
+	if r.IsExcluded() {
+		return KindExcluded
+	}
+	return KindReport
+}
+
 // CheckFilename errors if the filename is inconsistent with the report.
 func (r *Report) CheckFilename(filename string) (err error) {
 	defer derrors.Wrap(&err, "CheckFilename(%q)", filename)
 
-	dir := filepath.Base(filepath.Dir(filename)) // innermost folder
-	excluded := r.IsExcluded()
-
-	if excluded && dir != "excluded" {
-		return fmt.Errorf("%w (want %s, found %s)", errWrongDir, "excluded", dir)
+	kind, err := r.fileKind(filename)
+	if err != nil {
+		return err
 	}
+	if err := r.checkKind(kind); err != nil {
+		return err
+	}
+	return r.checkID(filename)
+}
 
-	if !excluded && dir != "reports" {
-		return fmt.Errorf("%w (want %s, found %s)", errWrongDir, "reports", dir)
+// fileKind returns the kind of report that belongs at filename,
+// based on its innermost directory.
+func (r *Report) fileKind(filename string) (ReportKind, error) {
+	switch dir := filepath.Base(filepath.Dir(filename)); dir {
+	case KindReport.String():
+		return KindReport, nil
+	case KindExcluded.String():
+		return KindExcluded, nil
+	default:
+		return 0, fmt.Errorf("%w (want %s, found %s)", errWrongDir, r.Kind(), dir)
 	}
+}
 
-	wantID := GoID(filename)
-	if r.ID != wantID {
-		return fmt.Errorf("%w (want %s, found %s)", errWrongID, wantID, r.ID)
+// checkKind errors if r is not of the given kind.
+func (r *Report) checkKind(kind ReportKind) error {
+	if want := r.Kind(); kind != want {
+		return fmt.Errorf("%w (want %s, found %s)", errWrongDir, want, kind)
 	}
+	return nil
+}
 
+// checkID errors if r's ID doesn't match filename.
+func (r *Report) checkID(filename string) error {
+	if wantID := GoID(filename); r.ID != wantID {
+		return fmt.Errorf("%w: filename %s does not match report id %s", errWrongID, wantID, r.ID)
+	}
 	return nil
 }
 
+// Severity is the severity of a lint issue.
+type Severity int
+
+const (
+	// SeverityError is the severity of a problem that must be fixed
+	// before a report can be published.
+	SeverityError Severity = iota
+	// SeverityWarning is the severity of a likely problem that should
+	// be checked by a reviewer, but does not block publication.
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+}
+
+// A LintIssue is a single problem found by a lint check.
+type LintIssue struct {
+	// File is the report file the issue was found in,
+	// or "" if not known.
+	File string
+	// Line is the line in File of the field the issue is about,
+	// or 0 if not known or if the issue isn't about a single field.
+	Line int
+	// Field is the path of the field of the report that the issue
+	// is about, such as "summary", "cve_metadata.id",
+	// "modules[0].packages[1]" or "references[2]", or "" if the
+	// issue isn't about a single field.
+	Field    string
+	Severity Severity
+	Msg      string
+	// Code identifies the check that found the issue, for listing in
+	// the lint_ignore field of a report (see LintIgnoreCodes). Only
+	// warnings have codes.
+	Code string
+}
+
+// String formats the issue as "file:line: msg", in the style of
+// compiler errors, omitting the file and line if they are not known.
+// The code of the issue, if any, follows the message in brackets.
+func (li LintIssue) String() string {
+	msg := li.Msg
+	if li.Severity != SeverityError {
+		msg = fmt.Sprintf("%s: %s", li.Severity, li.Msg)
+	}
+	if li.Code != "" {
+		msg = fmt.Sprintf("%s [%s]", msg, li.Code)
+	}
+	switch {
+	case li.File != "" && li.Line > 0:
+		msg = fmt.Sprintf("%s:%d: %s", li.File, li.Line, msg)
+	case li.File != "":
+		msg = fmt.Sprintf("%s: %s", li.File, msg)
+	}
+	return msg
+}
+
+// LintConfig configures optional lint checks.
+// The zero value enables all checks that are on by default.
+type LintConfig struct {
+	// AllowNoExternalIDs disables the warning for non-excluded reports
+	// that have no CVE, GHSA or advisory reference. Some vulnerabilities
+	// discovered by the Go team predate any external identifier.
+	AllowNoExternalIDs bool
+	// AllowAnyIntroduced disables the warnings about a redundant or
+	// suspicious introduced version in the first version range of a
+	// third-party module. Some reports legitimately use an explicit
+	// 0.0.0, for example.
+	AllowAnyIntroduced bool
+	// AllowPrereleaseFixed disables the warning for fixed versions
+	// that are prereleases, for reports that intentionally track a fix
+	// that is not yet in a stable release.
+	AllowPrereleaseFixed bool
+	// MaxLineLengths overrides the maximum length of the lines of
+	// fields that are checked for long lines, keyed by field name:
+	// "description" or "cve_metadata.description". Fields that are
+	// missing, or have a non-positive limit, use the default of 80.
+	MaxLineLengths map[string]int
+	// URLCache, if non-nil, enables a check that GitHub repositories
+	// linked from references are still available, using URLCache to
+	// make (and remember) the requests. Like the other online checks,
+	// it is only performed when a proxy client is provided.
+	URLCache *URLCache
+	// CheckPackages enables a check that each package of a third-party
+	// module exists in the module at its vulnerable_at version.
+	// This requires a proxy client, and is not enabled by default
+	// because it downloads the zip of each module from the proxy.
+	CheckPackages bool
+}
+
 // Lint checks the content of a Report and outputs a list of strings
 // representing lint errors.
-// TODO: It might make sense to include warnings or informational things
-// alongside errors, especially during for use during the triage process.
+//
+// Warnings are not included; use LintIssues to see them.
 func (r *Report) Lint(pc *proxy.Client) []string {
-	result := r.lint(pc)
+	return errorMsgs(r.LintIssues(pc, nil))
+}
+
+// LintIssues works like Lint, but returns both errors and warnings.
+// A nil cfg is equivalent to the zero LintConfig.
+func (r *Report) LintIssues(pc *proxy.Client, cfg *LintConfig) []LintIssue {
+	result := r.lint(pc, cfg)
 	if pc == nil {
-		result = append(result, "proxy client is nil; cannot perform all lint checks")
+		result = append(result, LintIssue{
+			Severity: SeverityError,
+			Msg:      "proxy client is nil; cannot perform all lint checks",
+		})
 	}
 	return result
 }
@@ -362,100 +1508,431 @@ func (r *Report) LintAsNotes(pc *proxy.Client) bool {
 }
 
 // LintOffline performs all lint checks that don't require a network connection.
+// Like Lint, it returns only errors.
 func (r *Report) LintOffline() []string {
-	return r.lint(nil)
+	return errorMsgs(r.lint(nil, nil))
 }
 
-func (r *Report) lint(pc *proxy.Client) []string {
-	var issues []string
+// LintAs performs the checks of LintOffline on r, which is meant to be
+// of the given kind, and returns both errors and warnings. It is an
+// error for r not to be of that kind (for example, to be excluded when
+// kind is KindReport).
+//
+// LintAs is for reports that are not in a file; for reports that are,
+// Publishable also checks that the filename matches the report.
+func (r *Report) LintAs(kind ReportKind) []LintIssue {
+	var issues []LintIssue
+	if err := r.checkKind(kind); err != nil {
+		issues = append(issues, LintIssue{Severity: SeverityError, Msg: err.Error()})
+	}
+	return append(issues, r.lint(nil, nil)...)
+}
 
-	addIssue := func(iss string) {
-		issues = append(issues, iss)
+// Publishable reports whether r, which was read from filename, is ready
+// to be published. It also returns all the issues found, for display.
+//
+// Only issues with SeverityError block publication. Issues with
+// SeverityWarning are returned, but do not affect the result.
+//
+// The checks are CheckFilename and the checks of LintOffline, together
+// with the warnings that LintIssues would report. The proxy checks of
+// Lint are not performed, so that Publishable never needs a network
+// connection; callers that have a proxy client should also run Lint.
+func (r *Report) Publishable(filename string) (bool, []LintIssue) {
+	var issues []LintIssue
+	addError := func(err error) {
+		issues = append(issues, LintIssue{Severity: SeverityError, Msg: err.Error()})
+	}
+	kind, err := r.fileKind(filename)
+	if err != nil {
+		addError(err)
+		kind = r.Kind() // already reported
+	}
+	if err := r.checkID(filename); err != nil {
+		addError(err)
+	}
+	issues = append(issues, r.LintAs(kind)...)
+	ok := true
+	for i := range issues {
+		issues[i].File = filename
+		if issues[i].Severity == SeverityError {
+			ok = false
+		}
 	}
+	return ok, issues
+}
 
-	if r.ID == "" {
-		addIssue("missing ID")
+// LintStructural performs a fast, structural validation of the report
+// in filename. It never uses the network, and is intended as a first
+// pass over large batches of reports before they are fully linted.
+//
+// The structural checks are:
+//   - required fields are present: the ID, at least one module (except
+//     for NOT_GO_CODE excluded reports), module and package paths, the
+//     summary of a non-excluded report, at least one CVE or GHSA for an
+//     excluded report, and the id and cwe of cve_metadata;
+//   - enumerated fields have allowed values: excluded reasons,
+//     reference types and database_specific.review_status;
+//   - identifiers (CVEs, GHSAs and related IDs), third-party import
+//     paths and reference URLs are syntactically valid, and reference
+//     URLs are absolute http(s) URLs with a host.
+//
+// All other checks are semantic, and are only performed by LintOffline
+// (which includes every check that doesn't need the network: version
+// ranges, the standard library, the content of the summary and
+// description, consistency between references and aliases, and
+// warnings), and by Lint and LintIssues (which add the proxy checks).
+// LintStructural does not check that the filename matches the report
+// either (see CheckFilename).
+//
+// A non-nil error means that the file could not be read, or is not
+// valid YAML containing only known report fields. Like Lint,
+// LintStructural returns only errors.
+func LintStructural(filename string) (_ []string, err error) {
+	defer derrors.Wrap(&err, "LintStructural(%q)", filename)
+
+	r, err := Read(filename)
+	if err != nil {
+		return nil, err
 	}
+	var lints []string
+	r.lintStructure(func(_, iss string) {
+		lints = append(lints, iss)
+	})
+	return lints, nil
+}
 
-	if r.IsExcluded() {
-		if !slices.Contains(ExcludedReasons, r.Excluded) {
-			addIssue(fmt.Sprintf("excluded reason (%q) is not a valid excluded reason (accepted: %v)", r.Excluded, ExcludedReasons))
+// errorMsgs returns the messages of the error-severity issues in issues.
+func errorMsgs(issues []LintIssue) []string {
+	var msgs []string
+	for _, iss := range issues {
+		if iss.Severity == SeverityError {
+			msgs = append(msgs, iss.Msg)
 		}
-		if r.Excluded != "NOT_GO_CODE" && len(r.Modules) == 0 {
-			addIssue("no modules")
+	}
+	return msgs
+}
+
+func (r *Report) lint(pc *proxy.Client, cfg *LintConfig) []LintIssue {
+	if cfg == nil {
+		cfg = &LintConfig{}
+	}
+	var issues []LintIssue
+
+	// Issues are recorded with the path of the field they are about
+	// (see LintIssue.Field), or "".
+	addIssueAt := func(field, iss string) {
+		issues = append(issues, LintIssue{Field: field, Severity: SeverityError, Msg: iss})
+	}
+	addIssue := func(iss string) {
+		addIssueAt("", iss)
+	}
+	fieldIssue := func(field string) func(string) {
+		return func(iss string) {
+			addIssueAt(field, iss)
 		}
-		if len(r.CVEs) == 0 && len(r.GHSAs) == 0 {
-			addIssue("excluded report must have at least one associated CVE or GHSA")
+	}
+	// checked records the codes of the warning checks that were
+	// performed, for reporting stale lint_ignore entries.
+	checked := make(map[string]bool)
+	warnAt := func(code string) func(field, iss string) {
+		checked[code] = true
+		return func(field, iss string) {
+			issues = append(issues, LintIssue{Field: field, Severity: SeverityWarning, Msg: iss, Code: code})
 		}
-	} else {
-		if len(r.Modules) == 0 {
-			addIssue("no modules")
+	}
+	fieldWarn := func(code, field string) func(string) {
+		addWarning := warnAt(code)
+		return func(iss string) {
+			addWarning(field, iss)
 		}
-		r.lintDescription(addIssue)
-		if r.Summary == "" {
-			addIssue("missing summary")
+	}
+	warn := func(code string) func(string) {
+		return fieldWarn(code, "")
+	}
+
+	r.lintStructure(addIssueAt)
+
+	if addWarning := fieldWarn("deprecated-schema", "schema_version"); r.IsDeprecatedSchema() {
+		addWarning(fmt.Sprintf("schema_version %d is deprecated; migrate the report to the current format (version %d)", r.SchemaVersion, CurrentSchemaVersion))
+	}
+
+	if r.IsExcluded() {
+		// Packages in a NOT_GO_CODE report suggest that the
+		// vulnerability does affect Go code, and the report needs to
+		// be triaged again.
+		if addWarning := warnAt("not-go-code-packages"); r.Excluded == "NOT_GO_CODE" {
+			for i, m := range r.Modules {
+				if len(m.Packages) > 0 {
+					addWarning(packageField(i, 0), "NOT_GO_CODE report should not list Go packages")
+					break
+				}
+			}
 		}
+	} else {
+		r.lintDescription(addIssueAt)
+		r.lintDescriptionModules(fieldWarn("description-module", "description"))
+		r.lintDatabaseSpecific(addIssue)
+		r.lintOSVReferences(addIssueAt)
+		if !cfg.AllowNoExternalIDs {
+			r.lintExternalIDs(warn("no-external-ids"))
+		}
+		addSummaryIssue := fieldIssue("summary")
 		if strings.HasPrefix(r.Summary, "TODO") {
-			addIssue("summary contains a TODO")
+			addSummaryIssue("summary contains a TODO")
 		}
 		if l := len(r.Summary); l > 100 {
-			addIssue(fmt.Sprintf("summary is too long: %d characters (max 100)", l))
+			addSummaryIssue(fmt.Sprintf("summary is too long: %d characters (max 100)", l))
 		}
 		if strings.HasSuffix(r.Summary, ".") {
-			addIssue("summary should not end in a period (should be a phrase, not a sentence)")
+			addSummaryIssue("summary should not end in a period (should be a phrase, not a sentence)")
 		}
+		r.lintSummaryIDs(fieldWarn("summary-id", "summary"))
 	}
 
 	isFirstParty := false
 	for i, m := range r.Modules {
+		mod := m.Module
+		if mod == "" {
+			mod = fmt.Sprintf("modules[%d]", i)
+		}
+		// Issues about the module, and about its package at index j.
+		addPackageIssue := func(j int, iss string) {
+			field := moduleField(i)
+			if j >= 0 {
+				field = packageField(i, j)
+			}
+			addIssueAt(field, fmt.Sprintf("%s: %v", mod, iss))
+		}
 		addPkgIssue := func(iss string) {
-			mod := m.Module
-			if mod == "" {
-				mod = fmt.Sprintf("modules[%d]", i)
+			addPackageIssue(-1, iss)
+		}
+		packageWarn := func(code string, j int) func(string) {
+			field := moduleField(i)
+			if j >= 0 {
+				field = packageField(i, j)
 			}
-			addIssue(fmt.Sprintf("%s: %v", mod, iss))
+			addWarning := fieldWarn(code, field)
+			return func(iss string) {
+				addWarning(fmt.Sprintf("%s: %v", mod, iss))
+			}
+		}
+		pkgWarn := func(code string) func(string) {
+			return packageWarn(code, -1)
 		}
 		if m.IsFirstParty() {
 			isFirstParty = true
-			m.lintStdLib(addPkgIssue)
+			m.lintStdLib(addPkgIssue, addPackageIssue)
 		} else {
-			m.lintThirdParty(addPkgIssue)
+			m.lintThirdParty(addPkgIssue, addPackageIssue)
+			if !r.IsExcluded() {
+				m.lintPathCasing(r.References, pkgWarn("module-path-casing"))
+				m.lintNoVersions(addPkgIssue, pkgWarn("no-versions"))
+			}
+			if !cfg.AllowAnyIntroduced {
+				m.lintIntroduced(pkgWarn("introduced"))
+			}
 			if pc != nil {
 				if err := m.checkModVersions(pc); err != nil {
 					addPkgIssue(err.Error())
+				} else {
+					if cfg.CheckPackages {
+						m.checkPackages(pc, addPackageIssue)
+					}
+					if !cfg.AllowAnyIntroduced {
+						m.checkIntroduced(pc, r.hasNotes(), pkgWarn("introduced-proxy"))
+					}
+				}
+				if len(m.Versions) == 0 && !r.IsExcluded() {
+					if err := m.checkModPath(pc); err != nil {
+						addPkgIssue(err.Error())
+					}
+				}
+				if err := m.checkVulnerableAt(pc, pkgWarn("vulnerable-at-incompatible")); err != nil {
+					addPkgIssue(err.Error())
 				}
 			}
 		}
-		for _, p := range m.Packages {
+		for j, p := range m.Packages {
+			// The issues found here are about package p.
+			addPkgIssue := func(iss string) {
+				addPackageIssue(j, iss)
+			}
+			pkgWarn := func(code string) func(string) {
+				return packageWarn(code, j)
+			}
 			if strings.HasPrefix(p.Package, fmt.Sprintf("%s/", stdlib.ToolchainModulePath)) && m.Module != stdlib.ToolchainModulePath {
 				addPkgIssue(fmt.Sprintf(`%q should be in module "%s", not %q`, p.Package, stdlib.ToolchainModulePath, m.Module))
 			}
 
+			p.lintSymbols(addPkgIssue)
+
 			if !r.IsExcluded() {
 				if m.VulnerableAt == "" && p.SkipFix == "" {
 					addPkgIssue(fmt.Sprintf("missing skip_fix and vulnerable_at: %q", p.Package))
 				}
+				// vulnerable_at applies to the whole module, so it is
+				// only redundant if no package of the module needs it.
+				if addWarning := pkgWarn("skip-fix-vulnerable-at"); m.VulnerableAt != "" && p.SkipFix != "" && m.allSkipFix() {
+					addWarning(fmt.Sprintf("package %s has both skip_fix and vulnerable_at set; prefer one", p.Package))
+				}
+				p.lintUnexportedSymbols(pkgWarn("unexported-symbols"))
+				if !m.IsFirstParty() {
+					p.lintNoSymbols(addPkgIssue, pkgWarn("no-symbols"))
+				}
 			}
 		}
 
 		m.lintVersions(addPkgIssue)
+		m.lintAdjacentRanges(pkgWarn("adjacent-ranges"))
+		if !cfg.AllowPrereleaseFixed {
+			m.lintPrereleaseFixed(pkgWarn("prerelease-fixed"))
+		}
 	}
 
-	r.lintLineLength("description", r.Description, addIssue)
+	r.lintLineLength("description", r.Description, cfg.maxLineLength("description"), fieldIssue("description"))
+	if r.CVEMetadata != nil {
+		r.lintLineLength("cve_metadata.description", r.CVEMetadata.Description, cfg.maxLineLength("cve_metadata.description"), fieldIssue("cve_metadata.description"))
+	}
+	lintWhitespace("summary", r.Summary, fieldIssue("summary"))
+	lintWhitespace("description", r.Description, fieldIssue("description"))
 	if r.CVEMetadata != nil {
-		r.lintLineLength("cve_metadata.description", r.CVEMetadata.Description, addIssue)
+		lintWhitespace("cve_metadata.description", r.CVEMetadata.Description, fieldIssue("cve_metadata.description"))
 	}
-	r.lintCVEs(addIssue)
-	r.lintGHSAs(addIssue)
-	r.lintRelated(addIssue)
+	r.lintCVEs(addIssueAt)
+	r.lintCWE(fieldIssue("cve_metadata.cwe"), fieldWarn("cwe-format", "cve_metadata.cwe"))
+	r.lintCVEDescription(fieldWarn("cve-description", "cve_metadata.description"))
+	r.lintRelated(addIssueAt)
 
 	if isFirstParty && !r.IsExcluded() {
-		r.lintStdLibLinks(addIssue)
+		r.lintStdLibLinks(addIssueAt, warn("stdlib-announce"))
+		r.lintMirrorModules(addIssueAt)
+	}
+
+	r.lintLinks(addIssueAt)
+	r.lintSelfReferences(addIssueAt)
+	r.lintGHSALinks(warnAt("ghsa-url"))
+	r.lintCVEAggregators(warnAt("cve-aggregator"))
+	if !isFirstParty {
+		r.lintFixHosts(warnAt("fix-host"))
+	}
+	r.lintFixCommits(warnAt("abbreviated-commit"))
+	r.lintFixBranches(addIssueAt)
+	r.lintFragments(warnAt("duplicate-fragment"))
+	if pc != nil && cfg.URLCache != nil && !r.IsExcluded() {
+		r.checkReferenceRepos(cfg.URLCache, warnAt("repo-unavailable"))
+	}
+
+	return r.applyLintIgnore(issues, checked)
+}
+
+// hasNotes reports whether r has any notes other than lint notes.
+func (r *Report) hasNotes() bool {
+	for _, n := range r.Notes {
+		if n.Type != NoteTypeLint {
+			return true
+		}
 	}
+	return false
+}
 
-	r.lintLinks(addIssue)
+// lintStructure performs the structural checks described in
+// LintStructural, reporting each issue with the path of the field
+// it is about (see LintIssue.Field).
+func (r *Report) lintStructure(addIssue func(field, msg string)) {
+	if r.ID == "" {
+		addIssue("id", "missing ID")
+	}
 
-	return issues
+	if r.IsExcluded() {
+		if !slices.Contains(ExcludedReasons, r.Excluded) {
+			addIssue("excluded", fmt.Sprintf("excluded reason (%q) is not a valid excluded reason (accepted: %v)", r.Excluded, ExcludedReasons))
+		}
+		if r.Excluded != "NOT_GO_CODE" && len(r.Modules) == 0 {
+			addIssue("modules", "no modules")
+		}
+		if len(r.CVEs) == 0 && len(r.GHSAs) == 0 {
+			addIssue("", "excluded report must have at least one associated CVE or GHSA")
+		}
+	} else {
+		if len(r.Modules) == 0 {
+			addIssue("modules", "no modules")
+		}
+		if r.Summary == "" {
+			addIssue("summary", "missing summary")
+		}
+	}
+
+	for i, m := range r.Modules {
+		addPackageIssue := func(j int, iss string) {
+			mod := m.Module
+			if mod == "" {
+				mod = moduleField(i)
+			}
+			field := moduleField(i)
+			if j >= 0 {
+				field = packageField(i, j)
+			}
+			addIssue(field, fmt.Sprintf("%s: %v", mod, iss))
+		}
+		m.lintStructure(addPackageIssue)
+	}
+
+	r.lintIDStructure(addIssue)
+
+	for _, ref := range r.References {
+		field := r.referenceField(ref)
+		switch {
+		case ref.Type == "":
+			addIssue(field, fmt.Sprintf("reference is missing a type (url: %s); likely %s", ref.URL, likelyReferenceType(ref.URL)))
+		case !slices.Contains(osv.ReferenceTypes, ref.Type):
+			addIssue(field, fmt.Sprintf("%q is not a valid reference type", ref.Type))
+		}
+		if strings.IndexFunc(ref.URL, isSpaceOrControl) >= 0 {
+			addIssue(field, fmt.Sprintf("%q: reference URL contains whitespace/control characters", ref.URL))
+		}
+		if u, err := url.ParseRequestURI(ref.URL); err != nil {
+			addIssue(field, fmt.Sprintf("%q is not a valid URL", ref.URL))
+		} else if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			// ParseRequestURI accepts URLs like "https:///path"
+			// and "//host/path".
+			addIssue(field, fmt.Sprintf("%q: reference URL must be absolute with a host", ref.URL))
+		}
+	}
+
+	if ds := r.DatabaseSpecific; ds != nil && ds.ReviewStatus != "" &&
+		!slices.Contains(osv.ReviewStatuses, ds.ReviewStatus) {
+		addIssue("database_specific.review_status", fmt.Sprintf("database_specific.review_status %q is not one of %v", ds.ReviewStatus, osv.ReviewStatuses))
+	}
+}
+
+// lintStructure checks that m's module and package paths are present
+// and, for third-party modules, that the package paths are well-formed.
+// Issues about a package are reported with its index in m.Packages,
+// and other issues with index -1.
+func (m *Module) lintStructure(addPackageIssue func(int, string)) {
+	if m.IsFirstParty() {
+		if len(m.Packages) == 0 {
+			addPackageIssue(-1, "missing package")
+		}
+	} else if m.Module == "" {
+		if m.hasOnlyStdLibPackages() {
+			addPackageIssue(-1, fmt.Sprintf("use module %q for standard library packages, not %q", stdlib.ModulePath, m.Module))
+		} else {
+			addPackageIssue(-1, "missing module")
+		}
+	}
+	for j, p := range m.Packages {
+		if p.Package == "" {
+			addPackageIssue(j, "missing package")
+			continue
+		}
+		if !m.IsFirstParty() && m.Module != "" {
+			if err := module.CheckImportPath(p.Package); err != nil {
+				addPackageIssue(j, err.Error())
+			}
+		}
+	}
 }
 
 func (m *Module) IsFirstParty() bool {
@@ -0,0 +1,359 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package report
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+	"golang.org/x/vulndb/internal/derrors"
+	"golang.org/x/vulndb/internal/proxy"
+	"gopkg.in/yaml.v3"
+)
+
+// LintDir lints all the YAML reports in the reports and excluded
+// directories of the vulndb repo rooted at root.
+//
+// Each report file is checked individually: it must be readable,
+// its filename must be consistent with its contents (see CheckFilename),
+// and it must pass Lint.
+//
+// In addition, LintDir performs global checks that depend on more than
+// one report:
+//   - no alias (CVE or GHSA) may be claimed by more than one report
+//     (see CheckAliasCollisions);
+//   - no report ID may appear in both the reports and excluded
+//     directories.
+//
+// If pc is nil, only checks that don't require a network connection
+// are performed. A nil cfg is equivalent to the zero LintConfig.
+//
+// The returned issues are sorted by file. File paths are relative to root.
+func LintDir(root string, pc *proxy.Client, cfg *LintConfig) (_ []LintIssue, err error) {
+	defer derrors.Wrap(&err, "LintDir(%q)", root)
+
+	files, err := reportFiles(root)
+	if err != nil {
+		return nil, err
+	}
+	var issues []LintIssue
+	reports := make(map[string]*Report)
+	for _, f := range files {
+		fileIssues, r := lintFile(root, f, pc, cfg)
+		issues = append(issues, fileIssues...)
+		if r != nil {
+			reports[f] = r
+		}
+	}
+	issues = append(issues, CheckAliasCollisions(reports)...)
+	issues = append(issues, dirCollisions(files, func(string) bool { return true })...)
+	sortByFile(issues)
+	return issues, nil
+}
+
+// LintChanged is like LintDir, but runs the per-file checks only on the
+// given changed files, which may be absolute or relative to root.
+// Files that are not YAML reports, or that no longer exist, are ignored.
+//
+// The global checks are still performed against the whole repo, using
+// a lightweight index built from the other reports, but only issues
+// involving the changed files are returned.
+func LintChanged(root string, changedFiles []string, pc *proxy.Client, cfg *LintConfig) (_ []LintIssue, err error) {
+	defer derrors.Wrap(&err, "LintChanged(%q)", root)
+
+	all, err := reportFiles(root)
+	if err != nil {
+		return nil, err
+	}
+	changed := make(map[string]bool)
+	for _, f := range changedFiles {
+		if filepath.IsAbs(f) {
+			if f, err = filepath.Rel(root, f); err != nil {
+				return nil, err
+			}
+		}
+		changed[filepath.Clean(f)] = true
+	}
+	return lintFiles(root, all, changed, pc, cfg), nil
+}
+
+// LintStream is like LintDir, but calls fn with the issues for each
+// report file as soon as the file has been linted, instead of returning
+// all the issues at once. Reports are not retained after they have been
+// linted, so memory use doesn't grow with the size of the repo.
+//
+// The global checks are supported by a pre-pass over all the files that
+// builds an index of only the identifiers of each report (see readIDs).
+//
+// fn is called exactly once for each report file, including files with
+// no issues (with a nil slice). The calls are sequential, in the same
+// order as the files appear in the result of LintDir (sorted by path
+// relative to root). For each file, the issues are also in the same
+// order as in LintDir: the per-file issues, followed by the global ones.
+// In other words, concatenating the issues passed to fn gives the
+// result of LintDir.
+//
+// A non-nil error means that the repo could not be read; fn may have
+// been called for some files before the error occurred.
+func LintStream(root string, pc *proxy.Client, cfg *LintConfig, fn func(file string, issues []LintIssue)) (err error) {
+	defer derrors.Wrap(&err, "LintStream(%q)", root)
+
+	files, err := reportFiles(root)
+	if err != nil {
+		return err
+	}
+	aliases := make(aliasIndex)
+	for _, f := range files {
+		ids, err := readIDs(filepath.Join(root, f))
+		if err != nil {
+			// Don't fail: the error is reported by lintFile, and a
+			// report that can't be read can't collide with others.
+			continue
+		}
+		aliases.add(f, ids.aliases())
+	}
+	global := make(map[string][]LintIssue)
+	includeAll := func(string) bool { return true }
+	for _, iss := range append(aliases.collisions(includeAll), dirCollisions(files, includeAll)...) {
+		global[iss.File] = append(global[iss.File], iss)
+	}
+	for _, f := range files {
+		issues, _ := lintFile(root, f, pc, cfg)
+		fn(f, append(issues, global[f]...))
+	}
+	return nil
+}
+
+// lintFiles runs the per-file checks on the files in all that are
+// in toLint, and the global checks on all files, returning only
+// issues for files in toLint.
+//
+// The global checks on files not in toLint use only the minimal
+// information returned by readIDs. Files not in toLint that can't be
+// read are skipped.
+func lintFiles(root string, all []string, toLint map[string]bool, pc *proxy.Client, cfg *LintConfig) []LintIssue {
+	var issues []LintIssue
+	aliases := make(aliasIndex)
+	for _, f := range all {
+		if !toLint[f] {
+			ids, err := readIDs(filepath.Join(root, f))
+			if err != nil {
+				// As in LintStream, don't fail: the file is not being
+				// linted, and a report that can't be read can't
+				// collide with others.
+				continue
+			}
+			aliases.add(f, ids.aliases())
+			continue
+		}
+		fileIssues, r := lintFile(root, f, pc, cfg)
+		issues = append(issues, fileIssues...)
+		if r != nil {
+			aliases.add(f, r.Aliases())
+		}
+	}
+	include := func(f string) bool { return toLint[f] }
+	issues = append(issues, aliases.collisions(include)...)
+	issues = append(issues, dirCollisions(all, include)...)
+	sortByFile(issues)
+	return issues
+}
+
+// sortByFile sorts issues by file, preserving the order of the issues
+// within each file.
+func sortByFile(issues []LintIssue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].File < issues[j].File
+	})
+}
+
+// CheckAliasCollisions checks that no alias (CVE or GHSA) is claimed by
+// more than one of the given reports, which are keyed by filename.
+//
+// Each colliding alias results in one issue for each report that claims
+// it. The issues are sorted by alias, and then by filename.
+func CheckAliasCollisions(reports map[string]*Report) []LintIssue {
+	aliases := make(aliasIndex)
+	for f, r := range reports {
+		aliases.add(f, r.Aliases())
+	}
+	return aliases.collisions(func(string) bool { return true })
+}
+
+// dirCollisions returns an issue for each report ID that has a file in
+// both the reports and excluded directories. The issue is attributed to
+// each of the files for which include returns true.
+//
+// The issues are sorted by ID, and then by filename.
+func dirCollisions(files []string, include func(file string) bool) []LintIssue {
+	dirs := make(map[string][]string) // ID to files
+	for _, f := range files {
+		id := GoID(f)
+		dirs[id] = append(dirs[id], f)
+	}
+	var issues []LintIssue
+	ids := maps.Keys(dirs)
+	sort.Strings(ids)
+	for _, id := range ids {
+		files := slices.Clone(dirs[id])
+		sort.Strings(files)
+		var inReports, inExcluded bool
+		for _, f := range files {
+			switch filepath.Dir(f) {
+			case filepath.FromSlash(YAMLDir):
+				inReports = true
+			case filepath.FromSlash(ExcludedDir):
+				inExcluded = true
+			}
+		}
+		if !inReports || !inExcluded {
+			continue
+		}
+		msg := fmt.Sprintf("%s exists in both %s/ and %s/", id, filepath.Base(YAMLDir), filepath.Base(ExcludedDir))
+		for _, f := range files {
+			if include(f) {
+				issues = append(issues, LintIssue{File: f, Severity: SeverityError, Msg: msg})
+			}
+		}
+	}
+	return issues
+}
+
+// lintFile performs the per-file checks on the report in file.
+// It also returns the report, or nil if it could not be read.
+func lintFile(root, file string, pc *proxy.Client, cfg *LintConfig) ([]LintIssue, *Report) {
+	var issues []LintIssue
+	addError := func(msg string) {
+		issues = append(issues, LintIssue{File: file, Severity: SeverityError, Msg: msg})
+	}
+	filename := filepath.Join(root, file)
+	r, err := Read(filename)
+	if err != nil {
+		addError(err.Error())
+		return issues, nil
+	}
+	// The file has already been read successfully, so an error here
+	// means only that the issues can't be given lines.
+	lines, _ := readFieldLines(filename)
+	if err := r.CheckFilename(file); err != nil {
+		addError(err.Error())
+	}
+	var lints []LintIssue
+	if pc == nil {
+		lints = r.lint(nil, cfg)
+	} else {
+		lints = r.LintIssues(pc, cfg)
+	}
+	for _, iss := range lints {
+		iss.File = file
+		iss.Line = lines[iss.Field]
+		issues = append(issues, iss)
+	}
+	return issues, r
+}
+
+// reportFiles returns the paths, relative to root, of all the YAML
+// reports in the repo rooted at root, in sorted order.
+func reportFiles(root string) ([]string, error) {
+	var files []string
+	for _, dir := range []string{YAMLDir, ExcludedDir} {
+		entries, err := os.ReadDir(filepath.Join(root, dir))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.IsDir() || filepath.Ext(e.Name()) != ".yaml" {
+				continue
+			}
+			files = append(files, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// reportIDs is the subset of a Report needed to perform the global
+// lint checks on reports that are not otherwise being linted.
+type reportIDs struct {
+	ID          string   `yaml:"id"`
+	CVEs        []string `yaml:"cves"`
+	GHSAs       []string `yaml:"ghsas"`
+	CVEMetadata *struct {
+		ID string `yaml:"id"`
+	} `yaml:"cve_metadata"`
+}
+
+func (ri *reportIDs) aliases() []string {
+	aliases := slices.Clone(ri.CVEs)
+	if ri.CVEMetadata != nil && ri.CVEMetadata.ID != "" {
+		aliases = append(aliases, ri.CVEMetadata.ID)
+	}
+	return append(aliases, ri.GHSAs...)
+}
+
+// readIDs reads the identifiers of the report in filename,
+// ignoring all other fields.
+func readIDs(filename string) (_ *reportIDs, err error) {
+	defer derrors.Wrap(&err, "readIDs(%q)", filename)
+
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var ri reportIDs
+	if err := yaml.Unmarshal(b, &ri); err != nil {
+		return nil, err
+	}
+	return &ri, nil
+}
+
+// aliasIndex is a map from aliases to the report files that claim them.
+type aliasIndex map[string][]string
+
+func (ai aliasIndex) add(file string, aliases []string) {
+	for _, a := range aliases {
+		if !slices.Contains(ai[a], file) {
+			ai[a] = append(ai[a], file)
+		}
+	}
+}
+
+// collisions returns an issue for each alias claimed by more than one
+// report file. The issue is attributed to each of the claiming files for
+// which include returns true.
+func (ai aliasIndex) collisions(include func(file string) bool) []LintIssue {
+	var issues []LintIssue
+	aliases := maps.Keys(ai)
+	sort.Strings(aliases)
+	for _, a := range aliases {
+		files := slices.Clone(ai[a])
+		if len(files) < 2 {
+			continue
+		}
+		sort.Strings(files)
+		var ids []string
+		for _, f := range files {
+			ids = append(ids, GoID(f))
+		}
+		var msg string
+		if len(ids) == 2 {
+			msg = fmt.Sprintf("%s is claimed by both %s and %s", a, ids[0], ids[1])
+		} else {
+			msg = fmt.Sprintf("%s is claimed by %d reports: %s", a, len(ids), strings.Join(ids, ", "))
+		}
+		for _, f := range files {
+			if include(f) {
+				issues = append(issues, LintIssue{File: f, Severity: SeverityError, Msg: msg})
+			}
+		}
+	}
+	return issues
+}
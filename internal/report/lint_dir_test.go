@@ -0,0 +1,305 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// writeTestRepo writes the given reports, keyed by path relative to
+// the root, to a new temporary directory and returns the root.
+func writeTestRepo(t *testing.T, reports map[string]Report) string {
+	t.Helper()
+
+	root := t.TempDir()
+	for _, dir := range []string{YAMLDir, ExcludedDir} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for f, r := range reports {
+		r := r
+		if err := r.Write(filepath.Join(root, f)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func testRepoReports() map[string]Report {
+	return map[string]Report{
+		"data/reports/GO-0000-0001.yaml": validReport(func(r *Report) {
+			r.ID = "GO-0000-0001"
+			r.CVEs = []string{"CVE-0000-0001"}
+		}),
+		"data/reports/GO-0000-0002.yaml": validReport(func(r *Report) {
+			r.ID = "GO-0000-0002"
+			r.CVEs = []string{"CVE-0000-0002"}
+			r.Summary = "" // lint error
+		}),
+		"data/reports/GO-0000-0003.yaml": validReport(func(r *Report) {
+			r.ID = "GO-0000-0003"
+			r.CVEs = []string{"CVE-0000-0001"} // duplicate of GO-0000-0001
+		}),
+		"data/excluded/GO-0000-0004.yaml": validExcludedReport(func(r *Report) {
+			r.ID = "GO-0000-0004"
+			r.CVEs = []string{"CVE-0000-0004"}
+		}),
+	}
+}
+
+func TestLintDir(t *testing.T) {
+	root := writeTestRepo(t, testRepoReports())
+	got, err := LintDir(root, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []LintIssue{
+		{
+			File:     "data/reports/GO-0000-0001.yaml",
+			Severity: SeverityError,
+			Msg:      "CVE-0000-0001 is claimed by both GO-0000-0001 and GO-0000-0003",
+		},
+		{
+			File:     "data/reports/GO-0000-0002.yaml",
+			Field:    "summary",
+			Severity: SeverityError,
+			Msg:      "missing summary",
+		},
+		{
+			File:     "data/reports/GO-0000-0003.yaml",
+			Severity: SeverityError,
+			Msg:      "CVE-0000-0001 is claimed by both GO-0000-0001 and GO-0000-0003",
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestLintStream(t *testing.T) {
+	root := writeTestRepo(t, testRepoReports())
+	var (
+		files []string
+		got   []LintIssue
+	)
+	if err := LintStream(root, nil, nil, func(file string, issues []LintIssue) {
+		files = append(files, file)
+		got = append(got, issues...)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	wantFiles := []string{
+		"data/excluded/GO-0000-0004.yaml",
+		"data/reports/GO-0000-0001.yaml",
+		"data/reports/GO-0000-0002.yaml",
+		"data/reports/GO-0000-0003.yaml",
+	}
+	if diff := cmp.Diff(wantFiles, files); diff != "" {
+		t.Errorf("files mismatch (-want, +got):\n%s", diff)
+	}
+	want, err := LintDir(root, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("issues mismatch with LintDir (-LintDir, +LintStream):\n%s", diff)
+	}
+}
+
+func TestLintChanged(t *testing.T) {
+	root := writeTestRepo(t, testRepoReports())
+	for _, test := range []struct {
+		desc    string
+		changed []string
+		want    []LintIssue
+	}{
+		{
+			desc:    "no changes",
+			changed: nil,
+			want:    nil,
+		},
+		{
+			desc: "non-report files ignored",
+			changed: []string{
+				"data/osv/GO-0000-0002.json",
+				"data/reports/GO-0000-9999.yaml", // deleted
+			},
+			want: nil,
+		},
+		{
+			desc:    "per-file issue",
+			changed: []string{"data/reports/GO-0000-0002.yaml"},
+			want: []LintIssue{
+				{
+					File:     "data/reports/GO-0000-0002.yaml",
+					Field:    "summary",
+					Severity: SeverityError,
+					Msg:      "missing summary",
+				},
+			},
+		},
+		{
+			desc:    "global issue against unchanged file",
+			changed: []string{filepath.Join(root, "data/reports/GO-0000-0003.yaml")},
+			want: []LintIssue{
+				{
+					File:     "data/reports/GO-0000-0003.yaml",
+					Severity: SeverityError,
+					Msg:      "CVE-0000-0001 is claimed by both GO-0000-0001 and GO-0000-0003",
+				},
+			},
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			got, err := LintChanged(root, test.changed, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLintChangedUnreadable(t *testing.T) {
+	root := writeTestRepo(t, testRepoReports())
+	// An unchanged report that can't be read doesn't stop the
+	// changed files from being linted, as in LintStream.
+	bad := filepath.Join(root, "data/reports/GO-0000-0009.yaml")
+	if err := os.WriteFile(bad, []byte("id: [GO-0000-0009\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LintChanged(root, []string{"data/reports/GO-0000-0002.yaml"}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []LintIssue{
+		{
+			File:     "data/reports/GO-0000-0002.yaml",
+			Field:    "summary",
+			Severity: SeverityError,
+			Msg:      "missing summary",
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestLintDirBothDirs(t *testing.T) {
+	reports := testRepoReports()
+	reports["data/reports/GO-0000-0004.yaml"] = validReport(func(r *Report) {
+		r.ID = "GO-0000-0004"
+		r.CVEs = []string{"CVE-0000-0005"}
+	})
+	root := writeTestRepo(t, reports)
+	const msg = "GO-0000-0004 exists in both reports/ and excluded/"
+	want := []LintIssue{
+		{File: "data/excluded/GO-0000-0004.yaml", Severity: SeverityError, Msg: msg},
+		{File: "data/reports/GO-0000-0004.yaml", Severity: SeverityError, Msg: msg},
+	}
+	filter := func(issues []LintIssue) []LintIssue {
+		var filtered []LintIssue
+		for _, iss := range issues {
+			if iss.Msg == msg {
+				filtered = append(filtered, iss)
+			}
+		}
+		return filtered
+	}
+
+	got, err := LintDir(root, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, filter(got)); diff != "" {
+		t.Errorf("LintDir: mismatch (-want, +got):\n%s", diff)
+	}
+
+	var streamed []LintIssue
+	if err := LintStream(root, nil, nil, func(_ string, issues []LintIssue) {
+		streamed = append(streamed, issues...)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(got, streamed); diff != "" {
+		t.Errorf("LintStream: mismatch with LintDir (-LintDir, +LintStream):\n%s", diff)
+	}
+
+	got, err = LintChanged(root, []string{"data/reports/GO-0000-0004.yaml"}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want[1:], filter(got)); diff != "" {
+		t.Errorf("LintChanged: mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestCheckAliasCollisions(t *testing.T) {
+	reports := map[string]*Report{
+		"data/reports/GO-0000-0003.yaml": {
+			CVEs:  []string{"CVE-0000-0001"},
+			GHSAs: []string{"GHSA-xxxx-yyyy-zzzz"},
+		},
+		"data/reports/GO-0000-0001.yaml": {
+			CVEs: []string{"CVE-0000-0001"},
+		},
+		"data/excluded/GO-0000-0002.yaml": {
+			CVEMetadata: &CVEMeta{ID: "CVE-0000-0001"},
+			GHSAs:       []string{"GHSA-xxxx-yyyy-zzzz"},
+		},
+		"data/reports/GO-0000-0004.yaml": {
+			CVEs: []string{"CVE-0000-0004"}, // ok
+		},
+	}
+	const msg3 = "CVE-0000-0001 is claimed by 3 reports: GO-0000-0002, GO-0000-0001, GO-0000-0003"
+	const msg2 = "GHSA-xxxx-yyyy-zzzz is claimed by both GO-0000-0002 and GO-0000-0003"
+	want := []LintIssue{
+		{File: "data/excluded/GO-0000-0002.yaml", Severity: SeverityError, Msg: msg3},
+		{File: "data/reports/GO-0000-0001.yaml", Severity: SeverityError, Msg: msg3},
+		{File: "data/reports/GO-0000-0003.yaml", Severity: SeverityError, Msg: msg3},
+		{File: "data/excluded/GO-0000-0002.yaml", Severity: SeverityError, Msg: msg2},
+		{File: "data/reports/GO-0000-0003.yaml", Severity: SeverityError, Msg: msg2},
+	}
+	// The output must be deterministic.
+	for i := 0; i < 5; i++ {
+		got := CheckAliasCollisions(reports)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Fatalf("mismatch (-want, +got):\n%s", diff)
+		}
+	}
+}
+
+func TestLintDirLines(t *testing.T) {
+	root := writeTestRepo(t, map[string]Report{
+		"data/reports/GO-0000-0001.yaml": validReport(func(r *Report) {
+			r.ID = "GO-0000-0001"
+			r.CVEs = []string{"CVE-0000-0001"}
+			r.Summary = "TODO: fill this out"
+		}),
+	})
+	got, err := LintDir(root, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []LintIssue{
+		{
+			File:     "data/reports/GO-0000-0001.yaml",
+			Line:     11,
+			Field:    "summary",
+			Severity: SeverityError,
+			Msg:      "summary contains a TODO",
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
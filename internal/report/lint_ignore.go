@@ -0,0 +1,79 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package report
+
+import (
+	"fmt"
+
+	"golang.org/x/exp/slices"
+)
+
+// LintIgnoreCodes maps the code of each kind of lint warning that can be
+// listed in a report's lint_ignore field to a description of the warning.
+//
+// The codes are stable: a code is never reused for a different check.
+var LintIgnoreCodes = map[string]string{
+	"abbreviated-commit":         "fix reference uses an abbreviated commit hash",
+	"adjacent-ranges":            "version ranges are adjacent and could be merged",
+	"cve-aggregator":             "reference uses a CVE aggregator site instead of NVD",
+	"cve-description":            "self-assigned CVE has no cve_metadata.description",
+	"cwe-format":                 "cve_metadata.cwe is not of the form \"CWE-N: Title\"",
+	"deprecated-schema":          "report uses a deprecated schema version",
+	"description-module":         "description references a module that is not affected",
+	"duplicate-fragment":         "references differ only by fragment",
+	"fix-host":                   "fix reference host doesn't match the module host",
+	"ghsa-url":                   "GHSA advisory URL is not canonical",
+	"introduced":                 "introduced version is redundant or suspicious",
+	"introduced-proxy":           "introduced version is suspicious given the module's releases",
+	"module-path-casing":         "module path casing doesn't match the references",
+	"not-go-code-packages":       "NOT_GO_CODE report lists Go packages",
+	"no-external-ids":            "report has no CVE, GHSA or advisory reference",
+	"no-symbols":                 "package has no symbols listed",
+	"no-versions":                "module has no versions",
+	"prerelease-fixed":           "fixed version is a prerelease",
+	"repo-unavailable":           "referenced repository is unavailable",
+	"skip-fix-vulnerable-at":     "package has both skip_fix and vulnerable_at set",
+	"summary-id":                 "summary contains a CVE or GHSA ID",
+	"stdlib-announce":            "standard library report has no golang-announce link",
+	"unexported-symbols":         "package lists only unexported symbols",
+	"vulnerable-at-incompatible": "vulnerable_at version lacks the +incompatible suffix",
+}
+
+// applyLintIgnore removes the warnings in issues whose codes are listed
+// in r.LintIgnore, and returns the remaining issues.
+//
+// It adds an error for each entry of r.LintIgnore that is not a known
+// code, and a warning for each entry that ignores a check that was
+// performed (according to checked) but found nothing, so that stale
+// entries are removed. Ignored checks that were not performed, such as
+// the online checks in an offline run, are not reported.
+func (r *Report) applyLintIgnore(issues []LintIssue, checked map[string]bool) []LintIssue {
+	used := make(map[string]bool)
+	issues = slices.DeleteFunc(issues, func(iss LintIssue) bool {
+		if iss.Severity == SeverityWarning && iss.Code != "" && slices.Contains(r.LintIgnore, iss.Code) {
+			used[iss.Code] = true
+			return true
+		}
+		return false
+	})
+	for i, code := range r.LintIgnore {
+		field := fmt.Sprintf("lint_ignore[%d]", i)
+		switch {
+		case LintIgnoreCodes[code] == "":
+			issues = append(issues, LintIssue{
+				Field:    field,
+				Severity: SeverityError,
+				Msg:      fmt.Sprintf("lint_ignore: %q is not a known lint code", code),
+			})
+		case checked[code] && !used[code]:
+			issues = append(issues, LintIssue{
+				Field:    field,
+				Severity: SeverityWarning,
+				Msg:      fmt.Sprintf("lint_ignore: %q matches no warnings; remove it", code),
+			})
+		}
+	}
+	return issues
+}
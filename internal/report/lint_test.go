@@ -7,9 +7,14 @@ package report
 import (
 	"errors"
 	"flag"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"golang.org/x/vulndb/internal/osv"
 	"golang.org/x/vulndb/internal/proxy"
 )
@@ -23,8 +28,9 @@ var (
 		{Type: osv.ReferenceTypeReport, URL: "https://go.dev/issue/12345"},
 	}
 	validCVEMetadata = &CVEMeta{
-		ID:  "CVE-0000-1111",
-		CWE: "CWE XXX: A CWE description",
+		ID:          "CVE-0000-1111",
+		CWE:         "CWE-000: A CWE description",
+		Description: "a CVE description",
 	}
 	noop = func(*Report) {}
 )
@@ -34,9 +40,11 @@ func validReport(f func(r *Report)) Report {
 		ID: "GO-0000-0000",
 		Modules: []*Module{{
 			Module:       "golang.org/x/net",
+			Versions:     []VersionRange{{Introduced: "0.2.0"}},
 			VulnerableAt: "1.2.3",
 			Packages: []*Package{{
 				Package: "golang.org/x/net/http2",
+				Symbols: []string{"Transport.RoundTrip"},
 			}},
 		}},
 		Description: "description",
@@ -65,6 +73,26 @@ func validStdReport(f func(r *Report)) Report {
 	return r
 }
 
+// mirrorStdModule and mirrorXModule are a standard library module and
+// a golang.org/x module that mirrors it.
+func mirrorStdModule() *Module {
+	return &Module{
+		Module:       "std",
+		Versions:     []VersionRange{{Fixed: "1.12.16"}, {Introduced: "1.13.0-0", Fixed: "1.13.7"}},
+		VulnerableAt: "1.13.6",
+		Packages:     []*Package{{Package: "crypto/x509"}},
+	}
+}
+
+func mirrorXModule() *Module {
+	return &Module{
+		Module:       "golang.org/x/crypto",
+		Versions:     []VersionRange{{Fixed: "0.1.0"}},
+		VulnerableAt: "0.0.1",
+		Packages:     []*Package{{Package: "golang.org/x/crypto/cryptobyte", Symbols: []string{"String.ReadASN1"}}},
+	}
+}
+
 func validExcludedReport(f func(r *Report)) Report {
 	r := Report{
 		ID:       "GO-0000-0000",
@@ -75,6 +103,15 @@ func validExcludedReport(f func(r *Report)) Report {
 	return r
 }
 
+// validOnlineReport is like validReport, but its module's vulnerable_at
+// version exists on the proxy.
+func validOnlineReport(f func(r *Report)) Report {
+	return validReport(func(r *Report) {
+		r.Modules[0].VulnerableAt = "0.2.0"
+		f(r)
+	})
+}
+
 func TestLint(t *testing.T) {
 	pc, err := proxy.NewTestClient(t, *realProxy)
 	if err != nil {
@@ -88,7 +125,7 @@ func TestLint(t *testing.T) {
 	}{
 		{
 			desc: "ok module-version pair",
-			report: validReport(func(r *Report) {
+			report: validOnlineReport(func(r *Report) {
 				r.Modules = append(r.Modules, &Module{
 					Module: "golang.org/x/net",
 					Versions: []VersionRange{
@@ -101,7 +138,7 @@ func TestLint(t *testing.T) {
 		},
 		{
 			desc: "invalid module-version pair",
-			report: validReport(func(r *Report) {
+			report: validOnlineReport(func(r *Report) {
 				r.Modules = append(r.Modules, &Module{
 					Module: "golang.org/x/net",
 					Versions: []VersionRange{
@@ -110,11 +147,56 @@ func TestLint(t *testing.T) {
 						},
 					}})
 			}),
-			want: []string{`version 0.2.5 does not exist`},
+			want: []string{`golang.org/x/net: introduced version 0.2.5 is not a released version`},
+		},
+		{
+			// The tag was deleted, so the version is not in the
+			// proxy's version list, but it can still be fetched.
+			desc: "unlisted version that resolves",
+			report: validOnlineReport(func(r *Report) {
+				r.Modules = append(r.Modules, &Module{
+					Module: "github.com/distribution/distribution",
+					Versions: []VersionRange{
+						{
+							Fixed: "2.8.2-beta.1+incompatible",
+						},
+					}})
+			}),
+			// No lints.
+		},
+		{
+			desc: "version before first release",
+			report: validOnlineReport(func(r *Report) {
+				r.Modules = append(r.Modules, &Module{
+					Module: "golang.org/x/net",
+					Versions: []VersionRange{
+						{
+							Introduced: "0.0.1",
+							Fixed:      "0.0.5",
+						},
+					}})
+			}),
+			want: []string{
+				"introduced version 0.0.1 predates the module's first release 0.1.0",
+				"fixed version 0.0.5 predates the module's first release 0.1.0",
+			},
+		},
+		{
+			desc: "unresolvable pseudo-version",
+			report: validOnlineReport(func(r *Report) {
+				r.Modules = append(r.Modules, &Module{
+					Module: "golang.org/x/net",
+					Versions: []VersionRange{
+						{
+							Fixed: "0.0.0-20220101000000-000000000000", // does not exist
+						},
+					}})
+			}),
+			want: []string{`version 0.0.0-20220101000000-000000000000 does not exist`},
 		},
 		{
 			desc: "non-canonical module",
-			report: validReport(func(r *Report) {
+			report: validOnlineReport(func(r *Report) {
 				r.Modules = append(r.Modules, &Module{
 					Module: "github.com/golang/vuln",
 					Versions: []VersionRange{
@@ -125,6 +207,31 @@ func TestLint(t *testing.T) {
 			}),
 			want: []string{`module is not canonical`},
 		},
+		{
+			desc: "non-canonical module with no versions",
+			report: validOnlineReport(func(r *Report) {
+				r.Modules = append(r.Modules, &Module{
+					Module: "github.com/golang/vuln",
+				})
+			}),
+			want: []string{`github.com/golang/vuln: module is not canonical at latest version 0.1.0 (canonical:golang.org/x/vuln)`},
+		},
+		{
+			desc: "unreleased vulnerable_at version",
+			report: validOnlineReport(func(r *Report) {
+				r.Modules[0].VulnerableAt = "0.2.5" // does not exist
+			}),
+			want: []string{`golang.org/x/net: vulnerable_at 0.2.5 is not a released version`},
+		},
+		{
+			desc: "canonical module with no versions",
+			report: validOnlineReport(func(r *Report) {
+				r.Modules = append(r.Modules, &Module{
+					Module: "golang.org/x/net",
+				})
+			}),
+			// No lints.
+		},
 	} {
 		test := test
 		t.Run(test.desc, func(t *testing.T) {
@@ -195,6 +302,40 @@ func TestLintOffline(t *testing.T) {
 			}),
 			want: []string{"missing summary"},
 		},
+		{
+			desc: "trailing whitespace",
+			report: validReport(func(r *Report) {
+				r.Summary = "Summary "
+				r.Description = "A description.\t\nSecond line."
+				r.CVEMetadata = &CVEMeta{
+					ID:          "CVE-0000-0000",
+					CWE:         "CWE-000",
+					Description: "No trailing whitespace.",
+				}
+			}),
+			want: []string{
+				"summary contains trailing whitespace",
+				"description contains trailing whitespace",
+			},
+		},
+		{
+			desc: "tabs",
+			report: validReport(func(r *Report) {
+				r.Description = "A\tdescription.\n\tIndented line."
+			}),
+			want: []string{"description contains a tab character"},
+		},
+		{
+			desc: "carriage returns",
+			report: validReport(func(r *Report) {
+				r.Summary = "Summary\r"
+				r.Description = "A description.\r\nSecond line."
+			}),
+			want: []string{
+				"summary contains a carriage return",
+				"description contains a carriage return",
+			},
+		},
 		{
 			desc: "summary has TODO",
 			report: validReport(func(r *Report) {
@@ -265,6 +406,16 @@ func TestLintOffline(t *testing.T) {
 			}),
 			want: []string{"module must be a prefix of package"},
 		},
+		{
+			desc: "third party: vulnerable_at_requires contains module",
+			report: validReport(func(r *Report) {
+				r.Modules[0].VulnerableAtRequires = []string{
+					"golang.org/x/text@v0.3.0",
+					"golang.org/x/net@v0.1.0",
+				}
+			}),
+			want: []string{"golang.org/x/net: vulnerable_at_requires must not contain the target module golang.org/x/net"},
+		},
 		{
 			desc: "third party: invalid import path",
 			report: validReport(func(r *Report) {
@@ -280,6 +431,69 @@ func TestLintOffline(t *testing.T) {
 			}),
 			want: []string{"missing package"},
 		},
+		{
+			desc: "reference to own report",
+			report: validReport(func(r *Report) {
+				r.ID = "GO-2023-0001"
+				r.References = []*Reference{
+					{Type: osv.ReferenceTypeWeb, URL: "https://pkg.go.dev/vuln/GO-2023-0001"},
+					{Type: osv.ReferenceTypeWeb, URL: "https://github.com/golang/vulndb/blob/master/data/reports/GO-2023-0001.yaml"},
+					{Type: osv.ReferenceTypeWeb, URL: "https://vuln.go.dev/ID/GO-2023-0001.json"},
+					// References to other reports are OK.
+					{Type: osv.ReferenceTypeWeb, URL: "https://pkg.go.dev/vuln/GO-2023-0002"},
+					{Type: osv.ReferenceTypeWeb, URL: "https://github.com/golang/vulndb/issues/1"},
+				}
+			}),
+			want: []string{
+				`"https://pkg.go.dev/vuln/GO-2023-0001": reference points back to the Go vuln database`,
+				`"https://github.com/golang/vulndb/blob/master/data/reports/GO-2023-0001.yaml": reference points back to the Go vuln database`,
+				`"https://vuln.go.dev/ID/GO-2023-0001.json": reference points back to the Go vuln database`,
+			},
+		},
+		{
+			desc: "versions with all_versions",
+			report: validReport(func(r *Report) {
+				r.Modules[0].AllVersions = true
+			}),
+			want: []string{"module golang.org/x/net has both all_versions and versions set; remove one"},
+		},
+		{
+			desc: "duplicate symbols",
+			report: validReport(func(r *Report) {
+				r.Modules[0].Packages[0].Symbols = []string{"Transport.RoundTrip", "Server.Serve", "Transport.RoundTrip"}
+			}),
+			want: []string{`duplicate symbol "Transport.RoundTrip" in package golang.org/x/net/http2`},
+		},
+		{
+			desc: "symbol is both function and type",
+			report: validReport(func(r *Report) {
+				r.Modules[0].Packages[0].Symbols = []string{"Server", "Server.Serve", "Server.Close"}
+			}),
+			want: []string{`symbol "Server" in package golang.org/x/net/http2 is listed as both a function and a type`},
+		},
+		{
+			desc: "standard library: toolchain versions",
+			report: validStdReport(func(r *Report) {
+				r.Modules[0].Versions = []VersionRange{{Introduced: "go1.20", Fixed: "go1.20.1"}}
+				r.Modules[0].VulnerableAt = "1.20.0"
+			}),
+			want: []string{
+				`stdlib version should be "1.20.0" not "go1.20"`,
+				`stdlib version should be "1.20.1" not "go1.20.1"`,
+				"version issue",
+			},
+		},
+		{
+			desc: "standard library: module version",
+			report: validStdReport(func(r *Report) {
+				r.Modules[0].Versions = []VersionRange{{Fixed: "1.20.1"}}
+				r.Modules[0].VulnerableAt = "v1.20.0"
+			}),
+			want: []string{
+				`stdlib version should be "1.20.0" not "v1.20.0"`,
+				"version issue",
+			},
+		},
 		{
 			desc: "toolchain: wrong module",
 			report: validStdReport(func(r *Report) {
@@ -288,6 +502,39 @@ func TestLintOffline(t *testing.T) {
 			}),
 			want: []string{`should be in module "cmd", not "std"`},
 		},
+		{
+			desc: "toolchain: fix in another repo",
+			report: validStdReport(func(r *Report) {
+				r.Modules[0].Module = "cmd"
+				r.Modules[0].Packages[0].Package = "cmd/go"
+				r.References = append(r.References,
+					&Reference{Type: osv.ReferenceTypeFix, URL: "https://go.googlesource.com/go/+/0123456789abcdef0123456789abcdef01234567"},
+					&Reference{Type: osv.ReferenceTypeFix, URL: "https://go.googlesource.com/tools/+/0123456789abcdef0123456789abcdef01234567"},
+				)
+			}),
+			want: []string{`"https://go.googlesource.com/tools/+/0123456789abcdef0123456789abcdef01234567": toolchain fix reference should be in the go repository`},
+		},
+		{
+			desc: "standard library: empty module",
+			report: validStdReport(func(r *Report) {
+				r.Modules[0].Module = ""
+			}),
+			want: []string{`use module "std" for standard library packages, not ""`},
+		},
+		{
+			desc: "standard library: wrong module",
+			report: validStdReport(func(r *Report) {
+				r.Modules[0].Module = "net/http"
+			}),
+			want: []string{`use module "std" for standard library packages, not "net/http"`},
+		},
+		{
+			desc: "standard library: x/ package",
+			report: validStdReport(func(r *Report) {
+				r.Modules[0].Packages[0].Package = "golang.org/x/net/http2"
+			}),
+			want: []string{`"golang.org/x/net/http2" is not a standard library package; use its own module, not "std"`},
+		},
 		{
 			desc: "overlapping version ranges",
 			report: validStdReport(func(r *Report) {
@@ -353,6 +600,40 @@ func TestLintOffline(t *testing.T) {
 			}),
 			want: []string{"cve_metadata.id is required", "cve_metadata.cwe is required"},
 		},
+		{
+			desc: "cve metadata description only",
+			report: validReport(func(r *Report) {
+				r.CVEs = nil
+				r.CVEMetadata = &CVEMeta{
+					ID:          "CVE-0000-1111",
+					CWE:         "CWE-000: A CWE description",
+					Description: "a CVE description",
+				}
+			}),
+			want: nil,
+		},
+		{
+			desc: "cve metadata conflicts with advisory",
+			report: validReport(func(r *Report) {
+				r.CVEs = nil
+				r.CVEMetadata = &CVEMeta{
+					ID:  "CVE-2023-0001",
+					CWE: "CWE-000: A CWE description",
+				}
+				r.References = []*Reference{
+					{Type: osv.ReferenceTypeAdvisory, URL: "https://nvd.nist.gov/vuln/detail/CVE-2023-0001"},
+					{Type: osv.ReferenceTypeAdvisory, URL: "https://nvd.nist.gov/vuln/detail/CVE-2023-0002"},
+					{Type: osv.ReferenceTypeAdvisory, URL: "https://cve.mitre.org/cgi-bin/cvename.cgi?name=CVE-2023-0003"},
+					// Only advisories are checked.
+					{Type: osv.ReferenceTypeWeb, URL: "https://nvd.nist.gov/vuln/detail/CVE-2023-0004"},
+				}
+			}),
+			want: []string{
+				"cve_metadata.id CVE-2023-0001 conflicts with advisory CVE-2023-0002",
+				"cve_metadata.id CVE-2023-0001 conflicts with advisory CVE-2023-0003",
+				"references should contain at most one advisory link",
+			},
+		},
 		{
 			desc: "bad cve metadata",
 			report: validReport(func(r *Report) {
@@ -364,6 +645,58 @@ func TestLintOffline(t *testing.T) {
 			}),
 			want: []string{"malformed cve_metadata.id identifier", "cve_metadata.cwe contains a TODO"},
 		},
+		{
+			desc: "fix references to branches",
+			report: validReport(func(r *Report) {
+				r.References = append(r.References,
+					&Reference{Type: osv.ReferenceTypeFix, URL: "https://go.googlesource.com/go/+/refs/heads/master"},
+					&Reference{Type: osv.ReferenceTypeFix, URL: "https://go.googlesource.com/net/+/master/html/parse.go"},
+					&Reference{Type: osv.ReferenceTypeFix, URL: "https://github.com/owner/repo/blob/main/file.go"},
+					&Reference{Type: osv.ReferenceTypeFix, URL: "https://github.com/owner/repo/tree/release-1.2"},
+					&Reference{Type: osv.ReferenceTypeFix, URL: "https://github.com/owner/repo/commits/main"},
+					// Not branches.
+					&Reference{Type: osv.ReferenceTypeFix, URL: "https://go.googlesource.com/go/+/0123456789abcdef0123456789abcdef01234567"},
+					&Reference{Type: osv.ReferenceTypeFix, URL: "https://go.googlesource.com/go/+/commit/0123456789abcdef0123456789abcdef01234567"},
+					&Reference{Type: osv.ReferenceTypeFix, URL: "https://go.googlesource.com/go/+/refs/tags/go1.21.0"},
+					&Reference{Type: osv.ReferenceTypeFix, URL: "https://go-review.googlesource.com/c/net/+/94838/9/html/parse.go"},
+					&Reference{Type: osv.ReferenceTypeFix, URL: "https://github.com/owner/repo/blob/0123456789abcdef0123456789abcdef01234567/file.go"},
+					&Reference{Type: osv.ReferenceTypeFix, URL: "https://github.com/owner/repo/commits/v0.16.1/"},
+					// Only fix references are checked.
+					&Reference{Type: osv.ReferenceTypeWeb, URL: "https://github.com/owner/repo/blob/main/SECURITY.md"},
+				)
+			}),
+			want: []string{
+				`"https://go.googlesource.com/go/+/refs/heads/master": fix reference points to a branch, not a specific commit`,
+				`"https://go.googlesource.com/net/+/master/html/parse.go": fix reference points to a branch, not a specific commit`,
+				`"https://github.com/owner/repo/blob/main/file.go": fix reference points to a branch, not a specific commit`,
+				`"https://github.com/owner/repo/tree/release-1.2": fix reference points to a branch, not a specific commit`,
+				`"https://github.com/owner/repo/commits/main": fix reference points to a branch, not a specific commit`,
+			},
+		},
+		{
+			desc: "multiple CWEs",
+			report: validReport(func(r *Report) {
+				r.CVEs = nil
+				r.CVEMetadata = &CVEMeta{
+					ID:          "CVE-0000-1111",
+					CWE:         "CWE-79, CWE-80",
+					Description: "a description",
+				}
+			}),
+			want: []string{"cve_metadata.cwe should contain a single CWE; found 2"},
+		},
+		{
+			desc: "no CWE",
+			report: validReport(func(r *Report) {
+				r.CVEs = nil
+				r.CVEMetadata = &CVEMeta{
+					ID:          "CVE-0000-1111",
+					CWE:         "Cross-site Scripting",
+					Description: "a description",
+				}
+			}),
+			want: []string{"cve_metadata.cwe should contain a single CWE; found 0"},
+		},
 		{
 			desc: "invalid reference type",
 			report: validReport(func(r *Report) {
@@ -372,7 +705,20 @@ func TestLintOffline(t *testing.T) {
 					URL:  "http://go.dev/",
 				})
 			}),
-			want: []string{"not a valid reference type"},
+			want: []string{
+				"not a valid reference type",
+				"reference would be dropped in OSV conversion: http://go.dev/",
+			},
+		},
+		{
+			desc: "reference altered in OSV",
+			report: validReport(func(r *Report) {
+				r.References = append(r.References, &Reference{
+					Type: osv.ReferenceTypeWeb,
+					URL:  "https://go.dev/\xff", // invalid UTF-8
+				})
+			}),
+			want: []string{"reference would be dropped in OSV conversion: https://go.dev/\xff"},
 		},
 		{
 			desc: "multiple advisory links",
@@ -387,6 +733,61 @@ func TestLintOffline(t *testing.T) {
 			}),
 			want: []string{"at most one advisory link"},
 		},
+		{
+			desc: "multiple advisory links for the same CVE",
+			report: validReport(func(r *Report) {
+				r.References = append(r.References, &Reference{
+					Type: "ADVISORY",
+					URL:  "https://nvd.nist.gov/vuln/detail/CVE-2023-1234",
+				}, &Reference{
+					Type: "ADVISORY",
+					URL:  "https://cve.mitre.org/cgi-bin/cvename.cgi?name=CVE-2023-1234",
+				})
+			}),
+			want: []string{"multiple advisory references for the same CVE-2023-1234"},
+		},
+		{
+			desc: "same url with different types",
+			report: validReport(func(r *Report) {
+				r.References = append(r.References,
+					&Reference{Type: osv.ReferenceTypeFix, URL: "https://example.com/b"},
+					&Reference{Type: osv.ReferenceTypeWeb, URL: "https://example.com/b#"},
+					&Reference{Type: osv.ReferenceTypeWeb, URL: "https://example.com/a"},
+					&Reference{Type: osv.ReferenceTypeReport, URL: "https://example.com/a?utm_source=x"},
+					&Reference{Type: osv.ReferenceTypeFix, URL: "https://example.com/a"},
+				)
+			}),
+			want: []string{
+				"url https://example.com/a is listed as FIX, REPORT and WEB",
+				"url https://example.com/b is listed as both FIX and WEB",
+				"reference URL contains tracking parameters",
+			},
+		},
+		{
+			desc: "tracking parameters",
+			report: validReport(func(r *Report) {
+				r.References = append(r.References, &Reference{
+					Type: "WEB",
+					URL:  "https://example.com/post?utm_source=newsletter",
+				})
+			}),
+			want: []string{"reference URL contains tracking parameters"},
+		},
+		{
+			desc: "published report with invalid ID",
+			report: validReport(func(r *Report) {
+				r.ID = "GO-ID-PENDING"
+				r.Published = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+			}),
+			want: []string{`database_specific.url "https://pkg.go.dev/vuln/GO-ID-PENDING" is not a valid Go advisory URL`},
+		},
+		{
+			desc: "invalid review status",
+			report: validReport(func(r *Report) {
+				r.DatabaseSpecific = &DatabaseSpecific{ReviewStatus: "MAYBE"}
+			}),
+			want: []string{`database_specific.review_status "MAYBE" is not one of`},
+		},
 		{
 			desc: "redundant advisory links",
 			report: validReport(func(r *Report) {
@@ -423,12 +824,14 @@ func TestLintOffline(t *testing.T) {
 					{Type: osv.ReferenceTypeReport, URL: "https://github.com/golang/go/issues/12345"},
 					{Type: osv.ReferenceTypeWeb, URL: "https://golang.org/xxx"},
 					{Type: osv.ReferenceTypeWeb, URL: "https://groups.google.com/forum/#!/golang-announce/12345/1/"},
+					{Type: osv.ReferenceTypeWeb, URL: "https://web.nvd.nist.gov/view/vuln/detail?vulnId=CVE-9999-0000"},
 				}
 			}),
 			want: []string{
+				`"https://web.nvd.nist.gov/view/vuln/detail?vulnId=CVE-9999-0000" should be "https://nvd.nist.gov/vuln/detail/CVE-9999-0000"`,
 				`"https://github.com/golang/go/issues/12345" should be "https://go.dev/issue/12345"`,
 				`"https://golang.org/xxx" should be "https://go.dev/xxx"`,
-				`"https://github.com/golang/go/commit/12345" should be "https://go.googlesource.com/+/12345"`,
+				`"https://github.com/golang/go/commit/12345" should be "https://go.googlesource.com/go/+/12345"`,
 				`"https://groups.google.com/forum/#!/golang-announce/12345/1/" should be "https://groups.google.com/g/golang-announce/c/12345/m/1/"`},
 		},
 		{
@@ -449,10 +852,65 @@ func TestLintOffline(t *testing.T) {
 				"references should contain an announcement link",
 				"web references should only contain announcement links",
 				// Unfixed link errors.
-				`"https://github.com/golang/go/commit/12345" should be "https://go.googlesource.com/+/12345"`,
+				`"https://go-review.googlesource.com/c/go/+/12345" should be "https://go.dev/cl/12345"`,
+				`"https://github.com/golang/go/commit/12345" should be "https://go.googlesource.com/go/+/12345"`,
 				`"https://github.com/golang/go/issues/12345" should be "https://go.dev/issue/12345"`,
 			},
 		},
+		{
+			desc: "standard library: gerrit link to a file",
+			report: validStdReport(func(r *Report) {
+				r.References = append(r.References, &Reference{
+					Type: osv.ReferenceTypeFix,
+					URL:  "https://go-review.googlesource.com/c/go/+/12345/2/src/net/http/server.go",
+				})
+			}),
+			want: []string{
+				"fix reference should link to the CL itself, in the form https://go.dev/cl/NUMBER",
+			},
+		},
+		{
+			desc: "standard library with mirror module",
+			report: validStdReport(func(r *Report) {
+				r.Modules = []*Module{mirrorStdModule(), mirrorXModule()}
+			}),
+			// No lints.
+		},
+		{
+			desc: "standard library with non-mirror module",
+			report: validStdReport(func(r *Report) {
+				r.Modules = []*Module{mirrorStdModule(), mirrorXModule(), {
+					Module:       "example.com/crypto",
+					Versions:     []VersionRange{{Fixed: "1.0.0"}},
+					VulnerableAt: "0.9.0",
+					Packages:     []*Package{{Package: "example.com/crypto", Symbols: []string{"Parse"}}},
+				}}
+			}),
+			want: []string{"module example.com/crypto can't be listed with the standard library; only golang.org/x modules can mirror it"},
+		},
+		{
+			desc: "standard library with unfixed mirror module",
+			report: validStdReport(func(r *Report) {
+				x := mirrorXModule()
+				x.Versions = nil
+				r.Modules = []*Module{mirrorStdModule(), x}
+			}),
+			want: []string{"module golang.org/x/crypto is not fixed, but the standard library is; versions of mirror modules should align"},
+		},
+		{
+			desc: "standard library: duplicate links",
+			report: validStdReport(func(r *Report) {
+				r.References = append(r.References,
+					&Reference{Type: osv.ReferenceTypeWeb, URL: "https://groups.google.com/g/golang-announce/c/12345"},
+					&Reference{Type: osv.ReferenceTypeWeb, URL: "https://go.dev/issue/12345"},
+				)
+			}),
+			want: []string{
+				`"https://groups.google.com/g/golang-announce/c/12345": duplicate announcement link`,
+				"url https://go.dev/issue/12345 is listed as both REPORT and WEB",
+				"web references should only contain announcement links",
+			},
+		},
 		{
 			desc: "invalid URL",
 			report: validReport(func(r *Report) {
@@ -467,6 +925,34 @@ func TestLintOffline(t *testing.T) {
 				`"go.dev/cl/12345" is not a valid URL`,
 			},
 		},
+		{
+			desc: "URL with whitespace or control characters",
+			report: validReport(func(r *Report) {
+				r.References = []*Reference{
+					{Type: osv.ReferenceTypeFix, URL: "https://go.dev/cl/12345 "},
+					{Type: osv.ReferenceTypeWeb, URL: "https://example.com/a\u200bb"},
+				}
+			}),
+			want: []string{
+				`"https://go.dev/cl/12345 ": reference URL contains whitespace/control characters`,
+				`"https://example.com/a\u200bb": reference URL contains whitespace/control characters`,
+			},
+		},
+		{
+			desc: "URL without host",
+			report: validReport(func(r *Report) {
+				r.References = []*Reference{
+					{Type: osv.ReferenceTypeFix, URL: "https:///cl/12345"},
+					{Type: osv.ReferenceTypeWeb, URL: "//go.dev/cl/12346"}, // scheme-relative
+					{Type: osv.ReferenceTypeWeb, URL: "ftp://go.dev/cl/12347"},
+				}
+			}),
+			want: []string{
+				`"https:///cl/12345": reference URL must be absolute with a host`,
+				`"//go.dev/cl/12346": reference URL must be absolute with a host`,
+				`"ftp://go.dev/cl/12347": reference URL must be absolute with a host`,
+			},
+		},
 		{
 			desc: "excluded missing/incorrect fields",
 			report: validExcludedReport(func(r *Report) {
@@ -525,12 +1011,868 @@ func TestLintOffline(t *testing.T) {
 	}
 }
 
-func checkLints(t *testing.T, got, want []string) {
-	var missing []string
-	for _, w := range want {
-		found := false
-		for _, g := range got {
-			if strings.Contains(g, w) {
+func TestLintLineLength(t *testing.T) {
+	line := strings.Repeat("word ", 18) + "word" // 94 characters
+	for _, test := range []struct {
+		desc string
+		cfg  *LintConfig
+		want []string
+	}{
+		{
+			desc: "default",
+			want: []string{
+				"description contains line > 80 characters long",
+				"cve_metadata.description contains line > 80 characters long",
+			},
+		},
+		{
+			desc: "per-field limit",
+			cfg: &LintConfig{MaxLineLengths: map[string]int{
+				"cve_metadata.description": 100,
+			}},
+			want: []string{"description contains line > 80 characters long"},
+		},
+		{
+			desc: "lower limit",
+			cfg: &LintConfig{MaxLineLengths: map[string]int{
+				"description":              60,
+				"cve_metadata.description": 100,
+			}},
+			want: []string{"description contains line > 60 characters long"},
+		},
+	} {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			r := validReport(func(r *Report) {
+				r.CVEs = nil
+				r.Description = line + "\n" + strings.Repeat("x", 120) // a single long word is OK
+				r.CVEMetadata = &CVEMeta{
+					ID:          "CVE-0000-1111",
+					CWE:         "CWE-000: A CWE description",
+					Description: line,
+				}
+			})
+			got := errorMsgs(r.lint(nil, test.cfg))
+			checkLints(t, got, test.want)
+		})
+	}
+}
+
+func TestLintLineLengthCarriageReturns(t *testing.T) {
+	line := strings.Repeat("word ", 15) + "word" // 79 characters
+	var got []string
+	var r Report
+	r.lintLineLength("description", line+"\r\n"+line+"\r\n", 79, func(msg string) {
+		got = append(got, msg)
+	})
+	if len(got) != 0 {
+		t.Errorf("got %q, want no issues", got)
+	}
+}
+
+func TestLintStructural(t *testing.T) {
+	for _, test := range []struct {
+		desc   string
+		report Report
+		want   []string
+	}{
+		{
+			desc:   "valid",
+			report: validReport(noop),
+			// No lints.
+		},
+		{
+			desc:   "valid excluded",
+			report: validExcludedReport(noop),
+			// No lints.
+		},
+		{
+			desc: "structural issues",
+			report: validReport(func(r *Report) {
+				r.Summary = ""
+				r.GHSAs = []string{"GHSA-123"}
+				r.Modules = append(r.Modules, &Module{
+					Packages: []*Package{{Package: "example.com/p"}},
+				})
+				r.References = append(r.References, &Reference{
+					Type: "NOT_A_TYPE",
+					URL:  "go.dev/cl/12345",
+				})
+				r.DatabaseSpecific = &DatabaseSpecific{ReviewStatus: "MAYBE"}
+			}),
+			want: []string{
+				"missing summary",
+				"GHSA-123 is not a valid GHSA",
+				"modules[1]: missing module",
+				`"NOT_A_TYPE" is not a valid reference type`,
+				`"go.dev/cl/12345" is not a valid URL`,
+				`database_specific.review_status "MAYBE" is not one of`,
+			},
+		},
+		{
+			desc: "missing reference types",
+			report: validReport(func(r *Report) {
+				r.References = append(r.References,
+					&Reference{URL: "https://go.dev/cl/12345"},
+					&Reference{URL: "https://github.com/owner/repo/issues/1"},
+					&Reference{URL: "https://nvd.nist.gov/vuln/detail/CVE-2023-1234"},
+					&Reference{URL: "https://github.com/advisories/GHSA-xxxx-yyyy-zzzz"},
+					&Reference{URL: "https://example.com/blog"},
+				)
+			}),
+			want: []string{
+				"reference is missing a type (url: https://go.dev/cl/12345); likely FIX",
+				"reference is missing a type (url: https://github.com/owner/repo/issues/1); likely REPORT",
+				"reference is missing a type (url: https://nvd.nist.gov/vuln/detail/CVE-2023-1234); likely ADVISORY",
+				"reference is missing a type (url: https://github.com/advisories/GHSA-xxxx-yyyy-zzzz); likely ADVISORY",
+				"reference is missing a type (url: https://example.com/blog); likely WEB",
+			},
+		},
+		{
+			desc: "semantic issues not checked",
+			report: validReport(func(r *Report) {
+				r.Summary = "TODO: fill in the summary."
+				r.Modules[0].Packages = append(r.Modules[0].Packages, &Package{
+					Package: "example.com/other/package",
+				})
+				r.Modules[0].VulnerableAt = "0.0.1"
+				r.References = append(r.References, &Reference{
+					Type: osv.ReferenceTypeAdvisory,
+					URL:  "https://github.com/advisories/GHSA-xxxx-yyyy-zzzz?utm_source=x",
+				})
+			}),
+			// No lints.
+		},
+	} {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "report.yaml")
+			if err := test.report.Write(filename); err != nil {
+				t.Fatal(err)
+			}
+			got, err := LintStructural(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkLints(t, got, test.want)
+		})
+	}
+}
+
+func TestLintStructuralUnknownField(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "report.yaml")
+	if err := os.WriteFile(filename, []byte("id: GO-0000-0000\nnot_a_field: true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LintStructural(filename); err == nil {
+		t.Errorf("LintStructural(%q) = nil error, want error", filename)
+	}
+}
+
+func TestLintPackages(t *testing.T) {
+	pc, err := proxy.NewTestClient(t, *realProxy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	quote := func(pkg string) Report {
+		return validReport(func(r *Report) {
+			r.Modules = []*Module{{
+				Module:       "rsc.io/quote",
+				VulnerableAt: "1.5.2",
+				Packages:     []*Package{{Package: pkg}},
+			}}
+		})
+	}
+	for _, test := range []struct {
+		desc   string
+		report Report
+		want   []string
+	}{
+		{
+			desc:   "package exists",
+			report: quote("rsc.io/quote"),
+			want:   nil,
+		},
+		{
+			desc:   "package does not exist",
+			report: quote("rsc.io/quote/buggy"),
+			want:   []string{"rsc.io/quote: package rsc.io/quote/buggy does not exist at v1.5.2"},
+		},
+	} {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			got := errorMsgs(test.report.LintIssues(pc, &LintConfig{CheckPackages: true}))
+			checkLints(t, got, test.want)
+		})
+	}
+}
+
+func TestLintIntroduced(t *testing.T) {
+	pc, err := proxy.NewTestClient(t, *realProxy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		desc   string
+		report Report
+		want   []string
+	}{
+		{
+			desc: "ok",
+			report: validReport(func(r *Report) {
+				r.Modules[0].Versions = []VersionRange{{Introduced: "0.2.0", Fixed: "0.8.0"}}
+				r.Modules[0].VulnerableAt = "0.7.0"
+			}),
+			// No warnings.
+		},
+		{
+			desc: "first release",
+			report: validReport(func(r *Report) {
+				r.Modules[0].Versions = []VersionRange{{Introduced: "0.1.0", Fixed: "0.8.0"}}
+				r.Modules[0].VulnerableAt = "0.7.0"
+			}),
+			want: []string{"introduced version 0.1.0 is the first release of golang.org/x/net"},
+		},
+		{
+			desc: "last release before fix",
+			report: validReport(func(r *Report) {
+				r.Modules[0].Versions = []VersionRange{{Introduced: "0.7.0", Fixed: "0.8.0"}}
+				r.Modules[0].VulnerableAt = "0.7.0"
+			}),
+			want: []string{"introduced version 0.7.0 is the last release before fixed version 0.8.0"},
+		},
+		{
+			desc: "last release before fix with note",
+			report: validReport(func(r *Report) {
+				r.Modules[0].Versions = []VersionRange{{Introduced: "0.7.0", Fixed: "0.8.0"}}
+				r.Modules[0].VulnerableAt = "0.7.0"
+				r.Notes = []*Note{{Body: "The vulnerable code was added in 0.7.0."}}
+			}),
+			// No warnings.
+		},
+	} {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			var got []string
+			for _, iss := range test.report.LintIssues(pc, nil) {
+				if iss.Severity == SeverityWarning {
+					got = append(got, iss.Msg)
+				}
+			}
+			checkLints(t, got, test.want)
+		})
+	}
+}
+
+func TestLintVulnerableAt(t *testing.T) {
+	pc, err := proxy.NewTestClient(t, *realProxy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		desc         string
+		vulnerableAt string
+		want         []string
+	}{
+		{
+			desc:         "released version",
+			vulnerableAt: "2.8.2+incompatible",
+			// No warnings.
+		},
+		{
+			desc:         "missing +incompatible",
+			vulnerableAt: "2.8.2",
+			want:         []string{"vulnerable_at 2.8.2 should be 2.8.2+incompatible"},
+		},
+	} {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			r := validReport(func(r *Report) {
+				r.Modules = []*Module{{
+					Module:       "github.com/distribution/distribution",
+					Versions:     []VersionRange{{Fixed: "2.8.3+incompatible"}},
+					VulnerableAt: test.vulnerableAt,
+					Packages: []*Package{{
+						Package: "github.com/distribution/distribution/registry/api/v2",
+						Symbols: []string{"Router"},
+					}},
+				}}
+			})
+			var got []string
+			for _, iss := range r.LintIssues(pc, nil) {
+				if iss.Severity == SeverityError {
+					t.Errorf("unexpected error: %s", iss.Msg)
+				}
+				if iss.Severity == SeverityWarning {
+					got = append(got, iss.Msg)
+				}
+			}
+			checkLints(t, got, test.want)
+		})
+	}
+}
+
+func TestLintWarnings(t *testing.T) {
+	for _, test := range []struct {
+		desc   string
+		report Report
+		cfg    *LintConfig
+		want   []string
+	}{
+		{
+			desc:   "no warnings",
+			report: validReport(noop),
+			// No warnings.
+		},
+		{
+			desc: "fix reference to module repo",
+			report: validReport(func(r *Report) {
+				r.Modules[0].Module = "github.com/owner/foo"
+				r.Modules[0].Packages[0].Package = "github.com/owner/foo/bar"
+				r.References = []*Reference{
+					{Type: osv.ReferenceTypeFix, URL: "https://github.com/Owner/Foo/pull/1"},
+					{Type: osv.ReferenceTypeFix, URL: "https://github.com/owner/foo/commit/0123456789abcdef0123456789abcdef01234567"},
+				}
+			}),
+			// No warnings.
+		},
+		{
+			desc: "fix reference to fork",
+			report: validReport(func(r *Report) {
+				r.Modules[0].Module = "github.com/owner/foo/v2"
+				r.Modules[0].Packages[0].Package = "github.com/owner/foo/v2/bar"
+				r.References = []*Reference{
+					{Type: osv.ReferenceTypeFix, URL: "https://github.com/otheruser/foo/pull/1"},
+					{Type: osv.ReferenceTypeWeb, URL: "https://github.com/otheruser/foo"}, // ok: not a fix
+				}
+			}),
+			want: []string{"fix reference host github.com/otheruser/foo doesn't match module host github.com/owner/foo"},
+		},
+		{
+			desc: "fix reference with underivable module host",
+			report: validReport(func(r *Report) {
+				r.References = []*Reference{
+					{Type: osv.ReferenceTypeFix, URL: "https://github.com/otheruser/foo/pull/1"},
+				}
+			}),
+			// No warnings: the host of golang.org/x/net can't be derived.
+		},
+		{
+			desc: "no external IDs",
+			report: validReport(func(r *Report) {
+				r.CVEs = nil
+			}),
+			want: []string{"report has no CVE, GHSA, or advisory reference"},
+		},
+		{
+			desc: "no external IDs allowed",
+			report: validReport(func(r *Report) {
+				r.CVEs = nil
+			}),
+			cfg: &LintConfig{AllowNoExternalIDs: true},
+			// No warnings.
+		},
+		{
+			desc: "advisory but no aliases",
+			report: validReport(func(r *Report) {
+				r.CVEs = nil
+				r.References = []*Reference{
+					{Type: osv.ReferenceTypeAdvisory, URL: "https://example.com/advisory"},
+				}
+			}),
+			// No warnings.
+		},
+		{
+			desc: "Go CVE but no other aliases",
+			report: validReport(func(r *Report) {
+				r.CVEs = nil
+				r.CVEMetadata = validCVEMetadata
+			}),
+			// No warnings.
+		},
+		{
+			desc: "stdlib golang-announce link",
+			report: validStdReport(func(r *Report) {
+				r.CVEMetadata = validCVEMetadata
+			}),
+			// No warnings.
+		},
+		{
+			desc: "stdlib golang-nuts link only",
+			report: validStdReport(func(r *Report) {
+				r.CVEMetadata = validCVEMetadata
+				r.References = []*Reference{
+					{Type: osv.ReferenceTypeFix, URL: "https://go.dev/cl/12345"},
+					{Type: osv.ReferenceTypeWeb, URL: "https://groups.google.com/g/golang-nuts/c/12345"},
+					{Type: osv.ReferenceTypeWeb, URL: "https://groups.google.com/g/golang-dev/c/12345"},
+					{Type: osv.ReferenceTypeReport, URL: "https://go.dev/issue/12345"},
+				}
+			}),
+			want: []string{"references should contain a golang-announce link for the security release"},
+		},
+		{
+			desc: "mixed-case module path",
+			report: validReport(func(r *Report) {
+				r.Modules[0].Module = "github.com/Sirupsen/logrus"
+				r.Modules[0].Packages[0].Package = "github.com/Sirupsen/logrus"
+			}),
+			want: []string{"github.com/Sirupsen/logrus: module path casing may be wrong: github.com/Sirupsen/logrus"},
+		},
+		{
+			desc: "mixed-case module path with different reference casing",
+			report: validReport(func(r *Report) {
+				r.Modules[0].Module = "github.com/Sirupsen/logrus"
+				r.Modules[0].Packages[0].Package = "github.com/Sirupsen/logrus"
+				r.References = []*Reference{{Type: osv.ReferenceTypeWeb, URL: "https://github.com/sirupsen/logrus/issues/1"}}
+			}),
+			want: []string{"module path casing may be wrong"},
+		},
+		{
+			desc: "mixed-case module path matching reference",
+			report: validReport(func(r *Report) {
+				r.Modules[0].Module = "github.com/Masterminds/vcs"
+				r.Modules[0].Packages[0].Package = "github.com/Masterminds/vcs"
+				r.References = []*Reference{{Type: osv.ReferenceTypeWeb, URL: "https://github.com/Masterminds/vcs/pull/1"}}
+			}),
+			// No warnings.
+		},
+		{
+			desc: "no symbols",
+			report: validReport(func(r *Report) {
+				r.Modules[0].Packages[0].Symbols = nil
+			}),
+			want: []string{"golang.org/x/net: package golang.org/x/net/http2 has no symbols listed; the entire package will be treated as vulnerable"},
+		},
+		{
+			desc: "no versions",
+			report: validReport(func(r *Report) {
+				r.Modules[0].Versions = nil
+			}),
+			want: []string{"golang.org/x/net: module golang.org/x/net has no version ranges; all versions will be considered affected"},
+		},
+		{
+			desc: "no versions with all_versions",
+			report: validReport(func(r *Report) {
+				r.Modules[0].Versions = nil
+				r.Modules[0].AllVersions = true
+			}),
+			// No warnings.
+		},
+		{
+			desc: "no versions in standard library",
+			report: validStdReport(func(r *Report) {
+				r.Modules[0].Versions = nil
+				r.CVEs = []string{"CVE-0000-0001"}
+			}),
+			// No warnings: the check only applies to third-party modules.
+		},
+		{
+			desc: "no symbols with whole_package",
+			report: validReport(func(r *Report) {
+				r.Modules[0].Packages[0].Symbols = nil
+				r.Modules[0].Packages[0].WholePackage = true
+			}),
+			// No warnings.
+		},
+		{
+			desc: "all symbols unexported",
+			report: validReport(func(r *Report) {
+				r.Modules[0].Packages[0].Symbols = []string{"parse", "conn.read"}
+			}),
+			want: []string{"golang.org/x/net: package golang.org/x/net/http2: all listed symbols are unexported; consider listing exported entry points"},
+		},
+		{
+			desc: "unexported symbols with exported derived symbols",
+			report: validReport(func(r *Report) {
+				r.Modules[0].Packages[0].Symbols = []string{"parse"}
+				r.Modules[0].Packages[0].DerivedSymbols = []string{"Parse"}
+			}),
+			// No warnings.
+		},
+		{
+			desc: "exported method of unexported type",
+			report: validReport(func(r *Report) {
+				r.Modules[0].Packages[0].Symbols = []string{"conn.Read"}
+			}),
+			// No warnings.
+		},
+		{
+			desc: "unexported symbols in package main",
+			report: validReport(func(r *Report) {
+				r.Modules[0].Packages[0].Symbols = []string{"serve"}
+				r.Modules[0].Packages[0].DerivedSymbols = []string{"main"}
+			}),
+			// No warnings.
+		},
+		{
+			desc: "skip_fix and vulnerable_at",
+			report: validReport(func(r *Report) {
+				r.Modules[0].Packages[0].SkipFix = "a reason"
+			}),
+			want: []string{"golang.org/x/net: package golang.org/x/net/http2 has both skip_fix and vulnerable_at set; prefer one"},
+		},
+		{
+			desc: "skip_fix and vulnerable_at used by another package",
+			report: validReport(func(r *Report) {
+				r.Modules[0].Packages[0].SkipFix = "a reason"
+				r.Modules[0].Packages = append(r.Modules[0].Packages, &Package{
+					Package: "golang.org/x/net/html",
+					Symbols: []string{"Parse"},
+				})
+			}),
+			// No warnings.
+		},
+		{
+			desc: "non-canonical GHSA advisory URL",
+			report: validReport(func(r *Report) {
+				r.GHSAs = []string{"GHSA-xxxx-yyyy-zzzz"}
+				r.References = []*Reference{
+					{Type: osv.ReferenceTypeAdvisory, URL: "https://github.com/golang/net/security/advisories/GHSA-xxxx-yyyy-zzzz"},
+				}
+			}),
+			want: []string{`"https://github.com/golang/net/security/advisories/GHSA-xxxx-yyyy-zzzz": non-canonical GHSA advisory URL; use "https://github.com/advisories/GHSA-xxxx-yyyy-zzzz"`},
+		},
+		{
+			desc: "GHSA advisory URL for unknown GHSA",
+			report: validReport(func(r *Report) {
+				r.References = []*Reference{
+					{Type: osv.ReferenceTypeAdvisory, URL: "https://github.com/golang/net/security/advisories/GHSA-xxxx-yyyy-zzzz"},
+				}
+			}),
+			// No warnings: it may be a repository advisory only.
+		},
+		{
+			desc: "full commit hashes",
+			report: validReport(func(r *Report) {
+				r.References = []*Reference{
+					{Type: osv.ReferenceTypeFix, URL: "https://go.googlesource.com/net/+/0123456789abcdef0123456789abcdef01234567"},
+					{Type: osv.ReferenceTypeFix, URL: "https://github.com/golang/net/commit/0123456789abcdef0123456789abcdef01234567"},
+					{Type: osv.ReferenceTypeFix, URL: "https://go-review.googlesource.com/c/net/+/123456"}, // not a commit
+					{Type: osv.ReferenceTypeWeb, URL: "https://github.com/golang/net/commit/0123456"},      // not a fix
+				}
+			}),
+			// No warnings.
+		},
+		{
+			desc: "abbreviated commit hashes",
+			report: validReport(func(r *Report) {
+				r.References = []*Reference{
+					{Type: osv.ReferenceTypeFix, URL: "https://go.googlesource.com/net/+/0123456"},
+					{Type: osv.ReferenceTypeFix, URL: "https://github.com/golang/net/commit/0123456789"},
+					{Type: osv.ReferenceTypeFix, URL: "https://gitlab.com/owner/repo/-/commit/abcdef0"},
+				}
+			}),
+			want: []string{
+				`"https://go.googlesource.com/net/+/0123456": fix reference uses abbreviated commit hash; use the full 40-character SHA`,
+				`"https://github.com/golang/net/commit/0123456789": fix reference uses abbreviated commit hash`,
+				`"https://gitlab.com/owner/repo/-/commit/abcdef0": fix reference uses abbreviated commit hash`,
+			},
+		},
+		{
+			desc: "references differing only by fragment",
+			report: validReport(func(r *Report) {
+				r.References = append(r.References,
+					&Reference{Type: osv.ReferenceTypeWeb, URL: "https://example.com/advisory#section"},
+					&Reference{Type: osv.ReferenceTypeWeb, URL: "https://example.com/advisory"},
+					&Reference{Type: osv.ReferenceTypeWeb, URL: "https://example.com/other#"},
+					&Reference{Type: osv.ReferenceTypeWeb, URL: "https://example.com/other"},
+				)
+			}),
+			want: []string{
+				`"https://example.com/advisory#section" and "https://example.com/advisory": references differ only by fragment; likely duplicate`,
+				`"https://example.com/other#" and "https://example.com/other": references differ only by fragment; likely duplicate`,
+			},
+		},
+		{
+			desc: "references to different sections of a page",
+			report: validReport(func(r *Report) {
+				r.References = append(r.References,
+					&Reference{Type: osv.ReferenceTypeWeb, URL: "https://example.com/CHANGELOG.md#v1.2.3"},
+					&Reference{Type: osv.ReferenceTypeWeb, URL: "https://example.com/CHANGELOG.md#v1.2.4"},
+				)
+			}),
+			// No warnings: single-page advisories may describe
+			// several vulnerabilities.
+		},
+		{
+			desc: "description references affected modules",
+			report: validReport(func(r *Report) {
+				r.Modules[0].Module = "github.com/owner/foo/v2"
+				r.Modules[0].Packages[0].Package = "github.com/owner/foo/v2/bar"
+				r.Description = "Package github.com/Owner/foo/v2/bar/baz, part of github.com/owner/foo, " +
+					"is vulnerable (see https://github.com/other/repo/issues/1)."
+			}),
+			// No warnings.
+		},
+		{
+			desc: "description references unrelated module",
+			report: validReport(func(r *Report) {
+				r.Modules[0].Module = "github.com/owner/foo"
+				r.Modules[0].Packages[0].Package = "github.com/owner/foo/bar"
+				r.Description = "A bug in github.com/other/pkg. Also github.com/owner/foobar and github.com/other/pkg."
+			}),
+			want: []string{
+				"description references github.com/other/pkg which is not an affected module",
+				"description references github.com/owner/foobar which is not an affected module",
+			},
+		},
+		{
+			desc: "CVE aggregator references",
+			report: validReport(func(r *Report) {
+				r.References = append(r.References,
+					&Reference{Type: osv.ReferenceTypeWeb, URL: "https://www.cvedetails.com/cve/CVE-2023-1234/"},
+					&Reference{Type: osv.ReferenceTypeWeb, URL: "https://vulmon.com/vulnerabilitydetails?qid=CVE-2023-5678"},
+					&Reference{Type: osv.ReferenceTypeWeb, URL: "https://www.cvedetails.com/vulnerability-list/vendor_id-1/Foo.html"}, // not a single CVE
+				)
+			}),
+			want: []string{
+				`"https://www.cvedetails.com/cve/CVE-2023-1234/": reference uses CVE aggregator cvedetails.com; prefer NVD (https://nvd.nist.gov/vuln/detail/CVE-2023-1234)`,
+				`"https://vulmon.com/vulnerabilitydetails?qid=CVE-2023-5678": reference uses CVE aggregator vulmon.com; prefer NVD (https://nvd.nist.gov/vuln/detail/CVE-2023-5678)`,
+			},
+		},
+		{
+			desc: "deprecated schema version",
+			report: validReport(func(r *Report) {
+				r.SchemaVersion = 1
+			}),
+			want: []string{"schema_version 1 is deprecated"},
+		},
+		{
+			desc: "redundant introduced version",
+			report: validReport(func(r *Report) {
+				r.Modules[0].Versions = []VersionRange{{Introduced: "0.0.0", Fixed: "1.2.4"}}
+			}),
+			want: []string{"golang.org/x/net: introduced version 0.0.0 is redundant; omit it to include all versions of golang.org/x/net"},
+		},
+		{
+			desc: "redundant introduced version (major version suffix)",
+			report: validReport(func(r *Report) {
+				r.Modules[0].Module = "example.com/m/v2"
+				r.Modules[0].VulnerableAt = "2.1.0"
+				r.Modules[0].Versions = []VersionRange{{Introduced: "2.0.0", Fixed: "2.1.1"}}
+				r.Modules[0].Packages[0].Package = "example.com/m/v2/p"
+			}),
+			want: []string{"introduced version 2.0.0 is redundant"},
+		},
+		{
+			desc: "redundant introduced version allowed",
+			report: validReport(func(r *Report) {
+				r.Modules[0].Versions = []VersionRange{{Introduced: "0.0.0", Fixed: "1.2.4"}}
+			}),
+			cfg: &LintConfig{AllowAnyIntroduced: true},
+			// No warnings.
+		},
+		{
+			desc: "non-canonical CWE",
+			report: validReport(func(r *Report) {
+				r.CVEs = nil
+				r.CVEMetadata = &CVEMeta{
+					ID:          "CVE-0000-1111",
+					CWE:         "CWE 400: Uncontrolled Resource Consumption",
+					Description: "a description",
+				}
+			}),
+			want: []string{`cve_metadata.cwe "CWE 400: Uncontrolled Resource Consumption" should have the form "CWE-N: Title"`},
+		},
+		{
+			desc: "NOT_GO_CODE with packages",
+			report: validExcludedReport(func(r *Report) {
+				r.Excluded = "NOT_GO_CODE"
+				r.Modules = []*Module{{
+					Module:   "example.com/module",
+					Packages: []*Package{{Package: "example.com/module/package"}},
+				}}
+			}),
+			want: []string{"NOT_GO_CODE report should not list Go packages"},
+		},
+		{
+			desc: "self-assigned CVE without CVE description",
+			report: validReport(func(r *Report) {
+				r.CVEs = nil
+				r.CVEMetadata = &CVEMeta{
+					ID:  "CVE-0000-1111",
+					CWE: "CWE-000: A CWE description",
+				}
+			}),
+			want: []string{"cve_metadata.description is required for self-assigned CVEs"},
+		},
+		{
+			desc: "self-assigned CVE without CVE description, ignored",
+			report: validReport(func(r *Report) {
+				r.CVEs = nil
+				r.CVEMetadata = &CVEMeta{
+					ID:  "CVE-0000-1111",
+					CWE: "CWE-000: A CWE description",
+				}
+				r.LintIgnore = []string{"cve-description"}
+			}),
+			// No warnings.
+		},
+		{
+			desc: "summary is a CVE",
+			report: validReport(func(r *Report) {
+				r.Summary = "CVE-2023-1234"
+			}),
+			want: []string{"summary should describe the vulnerability, not restate the identifier"},
+		},
+		{
+			desc: "summary contains a GHSA",
+			report: validReport(func(r *Report) {
+				r.Summary = "Fix for GHSA-xxxx-yyyy-zzzz in golang.org/x/net"
+			}),
+			want: []string{"summary should describe the vulnerability, not restate the identifier"},
+		},
+		{
+			desc: "adjacent version ranges",
+			report: validReport(func(r *Report) {
+				r.Modules[0].Versions = []VersionRange{
+					{Fixed: "0.2.0"},
+					{Introduced: "0.2.0", Fixed: "1.0.0"},
+					{Introduced: "1.1.0", Fixed: "1.2.0"},
+					{Introduced: "1.2.0"},
+				}
+			}),
+			want: []string{
+				"golang.org/x/net: adjacent version ranges [0,0.2.0) and [0.2.0,1.0.0) could be merged",
+				"golang.org/x/net: adjacent version ranges [1.1.0,1.2.0) and [1.2.0,) could be merged",
+			},
+		},
+		{
+			desc: "prerelease fixed version",
+			report: validReport(func(r *Report) {
+				r.Modules[0].Versions = []VersionRange{{Introduced: "0.2.0", Fixed: "1.3.0-rc.1"}}
+			}),
+			want: []string{"golang.org/x/net: fixed version 1.3.0-rc.1 is a prerelease; confirm the fix is in a stable release"},
+		},
+		{
+			desc: "pseudo-version fixed version",
+			report: validReport(func(r *Report) {
+				r.Modules[0].Versions = []VersionRange{{Introduced: "0.2.0", Fixed: "1.2.4-0.20230101000000-0123456789ab"}}
+			}),
+			// No warnings.
+		},
+		{
+			desc: "prerelease fixed version allowed",
+			report: validReport(func(r *Report) {
+				r.Modules[0].Versions = []VersionRange{{Introduced: "0.2.0", Fixed: "1.3.0-rc.1"}}
+			}),
+			cfg: &LintConfig{AllowPrereleaseFixed: true},
+			// No warnings.
+		},
+		{
+			desc: "introduced version of later range",
+			report: validReport(func(r *Report) {
+				r.Modules[0].VulnerableAt = "2.0.0"
+				r.Modules[0].Versions = []VersionRange{
+					{Fixed: "1.0.0"},
+					{Introduced: "2.0.0"},
+				}
+			}),
+			// No warnings.
+		},
+	} {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			var got []string
+			for _, iss := range test.report.LintIssues(nil, test.cfg) {
+				if iss.Severity == SeverityWarning {
+					got = append(got, iss.Msg)
+				}
+			}
+			checkLints(t, got, test.want)
+		})
+	}
+}
+
+func TestLintIgnore(t *testing.T) {
+	prerelease := func(r *Report) {
+		r.Modules[0].Versions = []VersionRange{{Introduced: "0.2.0", Fixed: "1.3.0-rc.1"}}
+	}
+	for _, test := range []struct {
+		desc   string
+		report Report
+		want   []LintIssue
+	}{
+		{
+			desc:   "warning has a code",
+			report: validReport(prerelease),
+			want: []LintIssue{{
+				Field:    "modules[0]",
+				Severity: SeverityWarning,
+				Msg:      "golang.org/x/net: fixed version 1.3.0-rc.1 is a prerelease; confirm the fix is in a stable release",
+				Code:     "prerelease-fixed",
+			}},
+		},
+		{
+			desc: "ignored",
+			report: validReport(func(r *Report) {
+				prerelease(r)
+				r.LintIgnore = []string{"prerelease-fixed"}
+			}),
+			// No issues.
+		},
+		{
+			desc: "stale",
+			report: validReport(func(r *Report) {
+				r.LintIgnore = []string{"prerelease-fixed"}
+			}),
+			want: []LintIssue{{
+				Field:    "lint_ignore[0]",
+				Severity: SeverityWarning,
+				Msg:      `lint_ignore: "prerelease-fixed" matches no warnings; remove it`,
+			}},
+		},
+		{
+			desc: "unknown code",
+			report: validReport(func(r *Report) {
+				r.LintIgnore = []string{"prerelease"}
+			}),
+			want: []LintIssue{{
+				Field:    "lint_ignore[0]",
+				Severity: SeverityError,
+				Msg:      `lint_ignore: "prerelease" is not a known lint code`,
+			}},
+		},
+		{
+			// The online checks are not performed without a proxy
+			// client, so their entries are not stale.
+			desc: "check not performed",
+			report: validReport(func(r *Report) {
+				r.LintIgnore = []string{"introduced-proxy", "repo-unavailable"}
+			}),
+			// No issues.
+		},
+		{
+			desc: "errors are not ignored",
+			report: validReport(func(r *Report) {
+				r.Summary = "TODO: summary"
+				r.LintIgnore = []string{"no-symbols"}
+			}),
+			want: []LintIssue{
+				{Field: "summary", Severity: SeverityError, Msg: "summary contains a TODO"},
+				{Field: "lint_ignore[0]", Severity: SeverityWarning, Msg: `lint_ignore: "no-symbols" matches no warnings; remove it`},
+			},
+		},
+	} {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			got := test.report.lint(nil, nil)
+			if diff := cmp.Diff(test.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func checkLints(t *testing.T, got, want []string) {
+	var missing []string
+	for _, w := range want {
+		found := false
+		for _, g := range got {
+			if strings.Contains(g, w) {
 				found = true
 				continue
 			}
@@ -567,6 +1909,119 @@ func checkLints(t *testing.T, got, want []string) {
 	}
 }
 
+func TestPublishable(t *testing.T) {
+	const filename = "data/reports/GO-0000-0000.yaml"
+	for _, test := range []struct {
+		desc     string
+		filename string
+		report   Report
+		want     bool
+		wantMsgs []string
+	}{
+		{
+			desc:     "publishable",
+			filename: filename,
+			report:   validReport(noop),
+			want:     true,
+		},
+		{
+			desc:     "warnings only",
+			filename: filename,
+			report: validReport(func(r *Report) {
+				r.CVEs = nil
+				r.CVEMetadata = validCVEMetadata
+				r.Modules[0].Versions = []VersionRange{{Introduced: "0.0.0", Fixed: "1.2.4"}}
+			}),
+			want:     true,
+			wantMsgs: []string{"introduced version 0.0.0 is redundant"},
+		},
+		{
+			desc:     "lint error",
+			filename: filename,
+			report: validReport(func(r *Report) {
+				r.Summary = ""
+			}),
+			want:     false,
+			wantMsgs: []string{"missing summary"},
+		},
+		{
+			desc:     "wrong filename",
+			filename: "data/excluded/GO-0000-0000.yaml",
+			report:   validReport(noop),
+			want:     false,
+			wantMsgs: []string{"report is in incorrect directory"},
+		},
+	} {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			got, issues := test.report.Publishable(test.filename)
+			if got != test.want {
+				t.Errorf("Publishable() = %t, want %t", got, test.want)
+			}
+			var msgs []string
+			for _, iss := range issues {
+				if iss.File != test.filename {
+					t.Errorf("issue %q: File = %q, want %q", iss.Msg, iss.File, test.filename)
+				}
+				msgs = append(msgs, iss.Msg)
+			}
+			checkLints(t, msgs, test.wantMsgs)
+		})
+	}
+}
+
+func TestLintAs(t *testing.T) {
+	excluded := validReport(func(r *Report) {
+		r.Excluded = "NOT_IMPORTABLE"
+		r.CVEs = []string{"CVE-0000-0001"}
+	})
+	for _, test := range []struct {
+		desc   string
+		report Report
+		kind   ReportKind
+		want   []string
+	}{
+		{
+			desc:   "report",
+			report: validReport(noop),
+			kind:   KindReport,
+		},
+		{
+			desc:   "excluded",
+			report: excluded,
+			kind:   KindExcluded,
+		},
+		{
+			desc:   "report as excluded",
+			report: validReport(noop),
+			kind:   KindExcluded,
+			want:   []string{"report is in incorrect directory (want reports, found excluded)"},
+		},
+		{
+			desc:   "excluded as report",
+			report: excluded,
+			kind:   KindReport,
+			want:   []string{"report is in incorrect directory (want excluded, found reports)"},
+		},
+		{
+			desc: "lint error",
+			report: validReport(func(r *Report) {
+				r.Summary = ""
+			}),
+			kind: KindReport,
+			want: []string{"missing summary"},
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			var msgs []string
+			for _, iss := range test.report.LintAs(test.kind) {
+				msgs = append(msgs, iss.Msg)
+			}
+			checkLints(t, msgs, test.want)
+		})
+	}
+}
+
 func TestCheckFilename(t *testing.T) {
 	for _, test := range []struct {
 		desc     string
@@ -583,6 +2038,15 @@ func TestCheckFilename(t *testing.T) {
 				}),
 			wantErr: errWrongID,
 		},
+		{
+			desc:     "missing ID",
+			filename: "data/reports/GO-0000-0000.yaml",
+			report: validReport(
+				func(r *Report) {
+					r.ID = ""
+				}),
+			wantErr: errWrongID,
+		},
 		{
 			desc:     "excluded in correct directory",
 			filename: "data/excluded/GO-0000-0000.yaml",
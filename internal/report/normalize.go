@@ -0,0 +1,61 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package report
+
+import (
+	"sort"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/vulndb/internal/version"
+)
+
+// Normalize puts r in a canonical order, so that equivalent reports
+// are written identically and diffs between versions of a report are
+// minimal. It sorts the CVEs and GHSAs, the modules by path, the
+// packages of each module by path, the symbols and derived symbols of
+// each package, and the version ranges of each module by version.
+//
+// Unlike Fix, Normalize never changes, adds or removes values; it only
+// reorders them. It is idempotent, and sorts stably, so elements that
+// compare equal (such as two modules with the same path) keep their
+// relative order.
+func (r *Report) Normalize() {
+	slices.Sort(r.CVEs)
+	slices.Sort(r.GHSAs)
+	sort.SliceStable(r.Modules, func(i, j int) bool {
+		return r.Modules[i].Module < r.Modules[j].Module
+	})
+	for _, m := range r.Modules {
+		m.sortVersions()
+		sort.SliceStable(m.Packages, func(i, j int) bool {
+			return m.Packages[i].Package < m.Packages[j].Package
+		})
+		for _, p := range m.Packages {
+			slices.Sort(p.Symbols)
+			slices.Sort(p.DerivedSymbols)
+		}
+	}
+}
+
+// sortVersions sorts m's version ranges by their introduced version,
+// or by their fixed version if they have none.
+func (m *Module) sortVersions() {
+	sort.SliceStable(m.Versions, func(i, j int) bool {
+		intro, fixed := m.Versions[i].Introduced, m.Versions[i].Fixed
+		intro2, fixed2 := m.Versions[j].Introduced, m.Versions[j].Fixed
+		switch {
+		case intro != "" && intro2 != "":
+			return version.Before(intro, intro2)
+		case intro != "" && fixed2 != "":
+			return version.Before(intro, fixed2)
+		case fixed != "" && intro2 != "":
+			return version.Before(fixed, intro2)
+		case fixed != "" && fixed2 != "":
+			return version.Before(fixed, fixed2)
+		default:
+			return false
+		}
+	})
+}
@@ -0,0 +1,94 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package report
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNormalize(t *testing.T) {
+	r := &Report{
+		CVEs:  []string{"CVE-2023-0002", "CVE-2023-0001"},
+		GHSAs: []string{"GHSA-yyyy-yyyy-yyyy", "GHSA-xxxx-xxxx-xxxx"},
+		Modules: []*Module{
+			{
+				Module: "golang.org/x/net",
+				Versions: []VersionRange{
+					{Introduced: "0.5.0", Fixed: "0.7.0"},
+					{Fixed: "0.2.0"},
+					{Introduced: "0.3.0", Fixed: "0.4.0"},
+				},
+				Packages: []*Package{
+					{Package: "golang.org/x/net/http2", Symbols: []string{"Server.Serve", "Framer.ReadFrame"}},
+					{
+						Package:        "golang.org/x/net/html",
+						Symbols:        []string{"parse", "Parse"},
+						DerivedSymbols: []string{"ParseFragment", "ParseFragmentWithOptions", "ParseWithOptions"},
+					},
+				},
+			},
+			{
+				Module:   "github.com/b/b",
+				Versions: []VersionRange{{Fixed: "1.0.0"}},
+			},
+			{
+				Module:   "github.com/a/a",
+				Versions: []VersionRange{{Fixed: "2.0.0"}},
+			},
+			{
+				// Same path as the previous module: the relative
+				// order is kept.
+				Module:   "github.com/a/a",
+				Versions: []VersionRange{{Fixed: "1.0.0"}},
+			},
+		},
+	}
+	want := &Report{
+		CVEs:  []string{"CVE-2023-0001", "CVE-2023-0002"},
+		GHSAs: []string{"GHSA-xxxx-xxxx-xxxx", "GHSA-yyyy-yyyy-yyyy"},
+		Modules: []*Module{
+			{
+				Module:   "github.com/a/a",
+				Versions: []VersionRange{{Fixed: "2.0.0"}},
+			},
+			{
+				Module:   "github.com/a/a",
+				Versions: []VersionRange{{Fixed: "1.0.0"}},
+			},
+			{
+				Module:   "github.com/b/b",
+				Versions: []VersionRange{{Fixed: "1.0.0"}},
+			},
+			{
+				Module: "golang.org/x/net",
+				Versions: []VersionRange{
+					{Fixed: "0.2.0"},
+					{Introduced: "0.3.0", Fixed: "0.4.0"},
+					{Introduced: "0.5.0", Fixed: "0.7.0"},
+				},
+				Packages: []*Package{
+					{
+						Package:        "golang.org/x/net/html",
+						Symbols:        []string{"Parse", "parse"},
+						DerivedSymbols: []string{"ParseFragment", "ParseFragmentWithOptions", "ParseWithOptions"},
+					},
+					{Package: "golang.org/x/net/http2", Symbols: []string{"Framer.ReadFrame", "Server.Serve"}},
+				},
+			},
+		},
+	}
+
+	r.Normalize()
+	if diff := cmp.Diff(want, r); diff != "" {
+		t.Errorf("Normalize() mismatch (-want +got):\n%s", diff)
+	}
+	// Normalizing again has no effect.
+	r.Normalize()
+	if diff := cmp.Diff(want, r); diff != "" {
+		t.Errorf("second Normalize() mismatch (-want +got):\n%s", diff)
+	}
+}
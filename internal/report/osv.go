@@ -6,6 +6,7 @@ package report
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -16,7 +17,9 @@ import (
 	"golang.org/x/exp/maps"
 	"golang.org/x/vulndb/internal/derrors"
 	"golang.org/x/vulndb/internal/osv"
+	"golang.org/x/vulndb/internal/osvutils"
 	"golang.org/x/vulndb/internal/stdlib"
+	"golang.org/x/vulndb/internal/version"
 )
 
 var (
@@ -63,7 +66,7 @@ func (r *Report) ToOSV(lastModified time.Time) osv.Entry {
 		Details:          toParagraphs(details),
 		Credits:          credits,
 		SchemaVersion:    SchemaVersion,
-		DatabaseSpecific: &osv.DatabaseSpecific{URL: GoAdvisory(r.ID)},
+		DatabaseSpecific: r.osvDatabaseSpecific(),
 	}
 
 	for _, m := range r.Modules {
@@ -79,6 +82,56 @@ func (r *Report) ToOSV(lastModified time.Time) osv.Entry {
 	return entry
 }
 
+// ValidateOSV converts r to OSV and validates the result with the
+// same checks used for entries in the Go vulnerability database
+// (except timestamps), returning the problems found. Each issue is
+// attributed to the report field that the invalid OSV field was
+// generated from.
+//
+// This catches problems that Lint doesn't, so it is meant to be
+// used in addition to it.
+func (r *Report) ValidateOSV() []LintIssue {
+	entry := r.ToOSV(time.Time{})
+	var issues []LintIssue
+	for _, fe := range osvutils.ValidateFields(&entry) {
+		issues = append(issues, LintIssue{
+			Severity: SeverityError,
+			Msg:      fmt.Sprintf("%s: invalid OSV: %v", r.osvFieldSource(fe.Field), fe.Err),
+		})
+	}
+	return issues
+}
+
+// osvFieldSource returns the name of the report field that
+// the given field of r's OSV entry is generated from.
+func (r *Report) osvFieldSource(field string) string {
+	var i int
+	if _, err := fmt.Sscanf(field, "affected[%d]", &i); err == nil && i < len(r.Modules) {
+		return fmt.Sprintf("modules[%d] (%s)", i, r.Modules[i].Module)
+	}
+	switch field {
+	case "affected":
+		return "modules"
+	case "details":
+		return "description"
+	case "aliases":
+		return "cves/ghsas"
+	case "database_specific":
+		return "id"
+	}
+	return field
+}
+
+// osvDatabaseSpecific returns the database_specific field
+// of the OSV entry for r.
+func (r *Report) osvDatabaseSpecific() *osv.DatabaseSpecific {
+	ds := &osv.DatabaseSpecific{URL: GoAdvisory(r.ID)}
+	if r.DatabaseSpecific != nil {
+		ds.ReviewStatus = r.DatabaseSpecific.ReviewStatus
+	}
+	return ds
+}
+
 func (r *Report) OSVFilename() string {
 	return filepath.Join(OSVDir, r.ID+".json")
 }
@@ -112,6 +165,44 @@ func ModulesForEntry(entry osv.Entry) []string {
 	return maps.Keys(mods)
 }
 
+// IsAffected reports whether version v of the module at modulePath is
+// affected by r. It returns false if the module is not in r.
+//
+// The standard library and toolchain may be given by their module
+// paths in reports ("std" and "cmd") or in OSV ("stdlib" and
+// "toolchain"), and their versions may be Go versions like "go1.20.1".
+// Versions may also have a "v" prefix, and may be pseudo-versions or
+// +incompatible versions. It is an error if v, or a version range of
+// the module, is not valid.
+func (r *Report) IsAffected(modulePath, v string) (_ bool, err error) {
+	defer derrors.Wrap(&err, "IsAffected(%q, %q)", modulePath, v)
+
+	switch modulePath {
+	case osv.GoStdModulePath:
+		modulePath = stdlib.ModulePath
+	case osv.GoCmdModulePath:
+		modulePath = stdlib.ToolchainModulePath
+	}
+	v = version.TrimPrefix(v)
+	if !version.IsValid(v) {
+		return false, fmt.Errorf("invalid version %q", v)
+	}
+	v = version.Canonical(v)
+	for _, m := range r.Modules {
+		if m.Module != modulePath {
+			continue
+		}
+		affected, err := osvutils.AffectsSemver(AffectedRanges(m.Versions), v)
+		if err != nil {
+			return false, err
+		}
+		if affected {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func AffectedRanges(versions []VersionRange) []osv.Range {
 	a := osv.Range{Type: osv.RangeTypeSemver}
 	if len(versions) == 0 || versions[0].Introduced == "" {
@@ -5,6 +5,7 @@
 package report
 
 import (
+	"fmt"
 	"path/filepath"
 	"reflect"
 	"testing"
@@ -75,6 +76,9 @@ func TestToOSV(t *testing.T) {
 			{Type: osv.ReferenceTypeFix, URL: "fix"},
 			{Type: osv.ReferenceTypeWeb, URL: "web"},
 		},
+		DatabaseSpecific: &DatabaseSpecific{
+			ReviewStatus: osv.ReviewStatusReviewed,
+		},
 	}
 
 	wantEntry := osv.Entry{
@@ -201,7 +205,10 @@ func TestToOSV(t *testing.T) {
 				Name: "gopherbot",
 			},
 		},
-		DatabaseSpecific: &osv.DatabaseSpecific{URL: "https://pkg.go.dev/vuln/GO-1991-0001"},
+		DatabaseSpecific: &osv.DatabaseSpecific{
+			URL:          "https://pkg.go.dev/vuln/GO-1991-0001",
+			ReviewStatus: osv.ReviewStatusReviewed,
+		},
 	}
 
 	gotEntry := r.ToOSV(time.Time{})
@@ -210,6 +217,24 @@ func TestToOSV(t *testing.T) {
 	}
 }
 
+func TestToOSVMirrorModule(t *testing.T) {
+	r := validStdReport(func(r *Report) {
+		r.Modules = []*Module{mirrorStdModule(), mirrorXModule()}
+	})
+	entry := r.ToOSV(time.Time{})
+	var got []string
+	for _, a := range entry.Affected {
+		got = append(got, fmt.Sprintf("%s %s: %s", a.Module.Ecosystem, a.Module.Path, formatRanges(a.Ranges)))
+	}
+	want := []string{
+		"Go stdlib: introduced 0, fixed 1.12.16, introduced 1.13.0-0, fixed 1.13.7",
+		"Go golang.org/x/crypto: introduced 0, fixed 0.1.0",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("affected mismatch (-want, +got):\n%s", diff)
+	}
+}
+
 func TestOSVFilename(t *testing.T) {
 	want := filepath.FromSlash("data/osv/GO-1999-0001.json")
 	r := &Report{ID: "GO-1999-0001"}
@@ -218,6 +243,109 @@ func TestOSVFilename(t *testing.T) {
 	}
 }
 
+func TestValidateOSV(t *testing.T) {
+	for _, test := range []struct {
+		desc   string
+		report Report
+		want   []string
+	}{
+		{
+			desc: "ok",
+			report: validReport(func(r *Report) {
+				r.References = []*Reference{{Type: osv.ReferenceTypeFix, URL: "https://go.dev/cl/12345"}}
+			}),
+			// No issues.
+		},
+		{
+			desc:   "no references",
+			report: validReport(func(r *Report) {}),
+			want:   []string{"references: invalid OSV: references field is empty"},
+		},
+		{
+			desc: "multiple issues",
+			report: validReport(func(r *Report) {
+				r.ID = "GO-0000-1"
+				r.Summary = ""
+				r.Description = ""
+				r.Modules = append(r.Modules, &Module{
+					Module:   "example.com/m",
+					Versions: []VersionRange{{Fixed: "v1.2"}},
+				})
+			}),
+			want: []string{
+				"summary: invalid OSV: summary field is empty",
+				"description: invalid OSV: details field is empty",
+				"references: invalid OSV: references field is empty",
+				"modules[1] (example.com/m): invalid OSV: invalid range event: invalid or non-canonical semver version (found v1.2)",
+				"id: invalid OSV: database_specific.URL must be a link to https://pkg.go.dev/vuln/<Go id> (found URL \"https://pkg.go.dev/vuln/GO-0000-1\")",
+			},
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			var got []string
+			for _, issue := range test.report.ValidateOSV() {
+				got = append(got, issue.String())
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("ValidateOSV() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIsAffected(t *testing.T) {
+	r := &Report{
+		Modules: []*Module{
+			{
+				Module:   "std",
+				Versions: []VersionRange{{Introduced: "1.20.0", Fixed: "1.20.2"}},
+			},
+			{
+				Module:   "example.com/m",
+				Versions: []VersionRange{{Fixed: "1.2.0"}, {Introduced: "2.0.0+incompatible", Fixed: "2.1.0+incompatible"}},
+			},
+			{
+				// A module that is listed twice.
+				Module:   "example.com/m",
+				Versions: []VersionRange{{Introduced: "3.0.0+incompatible"}},
+			},
+			{
+				Module:   "example.com/bad",
+				Versions: []VersionRange{{Fixed: "not-a-version"}},
+			},
+		},
+	}
+	for _, test := range []struct {
+		module, version string
+		want            bool
+		wantErr         bool
+	}{
+		{module: "std", version: "1.20.1", want: true},
+		{module: "stdlib", version: "go1.20.1", want: true},
+		{module: "std", version: "go1.20", want: true},
+		{module: "std", version: "1.20.2", want: false},
+		{module: "std", version: "go1.19", want: false},
+		{module: "example.com/m", version: "v1.1.0", want: true},
+		{module: "example.com/m", version: "1.2.0", want: false},
+		{module: "example.com/m", version: "v0.0.0-20230101000000-0123456789ab", want: true},
+		{module: "example.com/m", version: "v2.0.5+incompatible", want: true},
+		{module: "example.com/m", version: "v2.1.0+incompatible", want: false},
+		{module: "example.com/m", version: "v3.1.0+incompatible", want: true},
+		{module: "example.com/other", version: "1.0.0", want: false},
+		{module: "example.com/m", version: "bad", wantErr: true},
+		{module: "example.com/bad", version: "1.0.0", wantErr: true},
+	} {
+		got, err := r.IsAffected(test.module, test.version)
+		if (err != nil) != test.wantErr {
+			t.Errorf("IsAffected(%q, %q) error = %v, want error: %t", test.module, test.version, err, test.wantErr)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("IsAffected(%q, %q) = %t, want %t", test.module, test.version, got, test.want)
+		}
+	}
+}
+
 func TestAffectedRanges(t *testing.T) {
 	in := []VersionRange{
 		{
@@ -0,0 +1,232 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/vulndb/internal/derrors"
+	"golang.org/x/vulndb/internal/osv"
+)
+
+// publishedOSVURL is the URL prefix of the published OSV entries,
+// which are at publishedOSVURL + ID + ".json".
+var publishedOSVURL = "https://vuln.go.dev/ID/"
+
+// ErrNotPublished is returned by DiffPublishedOSV if there is
+// no published OSV entry for the report.
+var ErrNotPublished = errors.New("no published OSV entry")
+
+// DiffPublishedOSV fetches the published OSV entry for r from the
+// Go vulnerability database, and returns a human-readable description
+// of each change between it and the OSV entry generated from r.
+// The modified time is not compared.
+//
+// If the report hasn't been published yet, it returns an error
+// that is ErrNotPublished.
+func DiffPublishedOSV(r *Report) ([]string, error) {
+	return diffPublishedOSV(http.DefaultClient, r)
+}
+
+func diffPublishedOSV(client *http.Client, r *Report) (_ []string, err error) {
+	defer derrors.Wrap(&err, "DiffPublishedOSV(%q)", r.ID)
+
+	url := publishedOSVURL + r.ID + ".json"
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrNotPublished
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var published osv.Entry
+	if err := json.Unmarshal(b, &published); err != nil {
+		return nil, err
+	}
+	current := r.ToOSV(time.Time{})
+	return diffOSV(&published, &current), nil
+}
+
+// diffOSV returns a description of the changes from old to new,
+// ignoring the modified times.
+func diffOSV(old, new *osv.Entry) []string {
+	var diffs []string
+	add := func(format string, args ...any) {
+		diffs = append(diffs, fmt.Sprintf(format, args...))
+	}
+	diffString := func(field, o, n string) {
+		if o != n {
+			add("%s: %q -> %q", field, o, n)
+		}
+	}
+	diffList := func(field string, o, n []string) {
+		for _, s := range n {
+			if !slices.Contains(o, s) {
+				add("%s: added %s", field, s)
+			}
+		}
+		for _, s := range o {
+			if !slices.Contains(n, s) {
+				add("%s: removed %s", field, s)
+			}
+		}
+	}
+
+	diffString("schema_version", old.SchemaVersion, new.SchemaVersion)
+	diffString("id", old.ID, new.ID)
+	if !old.Published.Equal(new.Published.Time) {
+		add("published: %s -> %s", old.Published.Format(time.RFC3339), new.Published.Format(time.RFC3339))
+	}
+	diffString("withdrawn", formatTime(old.Withdrawn), formatTime(new.Withdrawn))
+	diffList("aliases", old.Aliases, new.Aliases)
+	diffList("related", old.Related, new.Related)
+	diffString("summary", old.Summary, new.Summary)
+	if old.Details != new.Details {
+		// Details are too long to show in full.
+		add("details: changed")
+	}
+
+	var oldMods, newMods []string
+	for _, a := range old.Affected {
+		oldMods = append(oldMods, a.Module.Path)
+	}
+	for _, a := range new.Affected {
+		newMods = append(newMods, a.Module.Path)
+	}
+	diffList("affected", oldMods, newMods)
+	for _, na := range new.Affected {
+		i := slices.IndexFunc(old.Affected, func(a osv.Affected) bool { return a.Module.Path == na.Module.Path })
+		if i < 0 {
+			continue
+		}
+		oa := old.Affected[i]
+		field := fmt.Sprintf("affected[%s]", na.Module.Path)
+		diffString(field+": ranges", formatRanges(oa.Ranges), formatRanges(na.Ranges))
+		diffs = append(diffs, diffPackages(field, oa.EcosystemSpecific, na.EcosystemSpecific)...)
+	}
+
+	var oldRefs, newRefs []string
+	for _, ref := range old.References {
+		oldRefs = append(oldRefs, fmt.Sprintf("%s %s", ref.Type, ref.URL))
+	}
+	for _, ref := range new.References {
+		newRefs = append(newRefs, fmt.Sprintf("%s %s", ref.Type, ref.URL))
+	}
+	diffList("references", oldRefs, newRefs)
+
+	var oldCredits, newCredits []string
+	for _, c := range old.Credits {
+		oldCredits = append(oldCredits, c.Name)
+	}
+	for _, c := range new.Credits {
+		newCredits = append(newCredits, c.Name)
+	}
+	diffList("credits", oldCredits, newCredits)
+
+	var oldDS, newDS osv.DatabaseSpecific
+	if old.DatabaseSpecific != nil {
+		oldDS = *old.DatabaseSpecific
+	}
+	if new.DatabaseSpecific != nil {
+		newDS = *new.DatabaseSpecific
+	}
+	diffString("database_specific.url", oldDS.URL, newDS.URL)
+	diffString("database_specific.review_status", string(oldDS.ReviewStatus), string(newDS.ReviewStatus))
+
+	return diffs
+}
+
+// diffPackages returns a description of the changes to the packages
+// of an affected module, prefixing each with field.
+func diffPackages(field string, old, new *osv.EcosystemSpecific) []string {
+	var oldPkgs, newPkgs []osv.Package
+	if old != nil {
+		oldPkgs = old.Packages
+	}
+	if new != nil {
+		newPkgs = new.Packages
+	}
+	find := func(pkgs []osv.Package, path string) *osv.Package {
+		for i := range pkgs {
+			if pkgs[i].Path == path {
+				return &pkgs[i]
+			}
+		}
+		return nil
+	}
+
+	var diffs []string
+	for _, np := range newPkgs {
+		op := find(oldPkgs, np.Path)
+		if op == nil {
+			diffs = append(diffs, fmt.Sprintf("%s: added package %s", field, np.Path))
+			continue
+		}
+		for _, l := range []struct {
+			name string
+			o, n []string
+		}{
+			{"symbols", op.Symbols, np.Symbols},
+			{"goos", op.GOOS, np.GOOS},
+			{"goarch", op.GOARCH, np.GOARCH},
+		} {
+			for _, s := range l.n {
+				if !slices.Contains(l.o, s) {
+					diffs = append(diffs, fmt.Sprintf("%s: package %s: added %s %s", field, np.Path, l.name, s))
+				}
+			}
+			for _, s := range l.o {
+				if !slices.Contains(l.n, s) {
+					diffs = append(diffs, fmt.Sprintf("%s: package %s: removed %s %s", field, np.Path, l.name, s))
+				}
+			}
+		}
+	}
+	for _, op := range oldPkgs {
+		if find(newPkgs, op.Path) == nil {
+			diffs = append(diffs, fmt.Sprintf("%s: removed package %s", field, op.Path))
+		}
+	}
+	return diffs
+}
+
+// formatRanges returns a compact description of ranges,
+// e.g. "introduced 0, fixed 1.2.3".
+func formatRanges(ranges []osv.Range) string {
+	var events []string
+	for _, r := range ranges {
+		for _, e := range r.Events {
+			if e.Introduced != "" {
+				events = append(events, "introduced "+e.Introduced)
+			}
+			if e.Fixed != "" {
+				events = append(events, "fixed "+e.Fixed)
+			}
+		}
+	}
+	return strings.Join(events, ", ")
+}
+
+func formatTime(t *osv.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
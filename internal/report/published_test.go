@@ -0,0 +1,102 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vulndb/internal/osv"
+)
+
+func TestDiffPublishedOSV(t *testing.T) {
+	published := validReport(func(r *Report) {
+		r.ID = "GO-2023-0001"
+		r.Published = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+		r.References = []*Reference{{Type: osv.ReferenceTypeFix, URL: "https://go.dev/cl/1"}}
+	})
+	entry := published.ToOSV(time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC))
+	b, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ID/GO-2023-0001.json", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write(b)
+	})
+	mux.HandleFunc("/ID/GO-2023-0002.json", func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	})
+	s := httptest.NewServer(mux)
+	defer s.Close()
+	defer func(u string) { publishedOSVURL = u }(publishedOSVURL)
+	publishedOSVURL = s.URL + "/ID/"
+
+	t.Run("unchanged", func(t *testing.T) {
+		r := published
+		got, err := diffPublishedOSV(s.Client(), &r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 0 {
+			t.Errorf("got changes %q, want none", got)
+		}
+	})
+
+	t.Run("changed", func(t *testing.T) {
+		r := published
+		r.Summary = "a new summary"
+		r.GHSAs = []string{"GHSA-xxxx-yyyy-zzzz"}
+		r.Modules = []*Module{{
+			Module:   "golang.org/x/net",
+			Versions: []VersionRange{{Fixed: "1.2.4"}},
+			Packages: []*Package{{
+				Package: "golang.org/x/net/http2",
+				Symbols: []string{"Server.Serve"},
+			}, {
+				Package: "golang.org/x/net/html",
+			}},
+		}, {
+			Module: "example.com/m",
+		}}
+		r.References = append(r.References, &Reference{Type: osv.ReferenceTypeWeb, URL: "https://example.com"})
+		got, err := diffPublishedOSV(s.Client(), &r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{
+			"aliases: added GHSA-xxxx-yyyy-zzzz",
+			`summary: "a summary" -> "a new summary"`,
+			"affected: added example.com/m",
+			`affected[golang.org/x/net]: ranges: "introduced 0.2.0" -> "introduced 0, fixed 1.2.4"`,
+			"affected[golang.org/x/net]: package golang.org/x/net/http2: added symbols Server.Serve",
+			"affected[golang.org/x/net]: package golang.org/x/net/http2: removed symbols Transport.RoundTrip",
+			"affected[golang.org/x/net]: added package golang.org/x/net/html",
+			"references: added WEB https://example.com",
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("mismatch (-want, +got):\n%s", diff)
+		}
+	})
+
+	t.Run("not published", func(t *testing.T) {
+		r := validReport(func(r *Report) { r.ID = "GO-2023-9999" })
+		if _, err := diffPublishedOSV(s.Client(), &r); !errors.Is(err, ErrNotPublished) {
+			t.Errorf("got error %v, want %v", err, ErrNotPublished)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		r := validReport(func(r *Report) { r.ID = "GO-2023-0002" })
+		if _, err := diffPublishedOSV(s.Client(), &r); err == nil || errors.Is(err, ErrNotPublished) {
+			t.Errorf("got error %v, want an error other than %v", err, ErrNotPublished)
+		}
+	})
+}
@@ -41,3 +41,35 @@ func referenceFromUrl(url string) *Reference {
 		URL:  url,
 	}
 }
+
+// likelyReferenceType returns the type that a reference to url most
+// likely has. It is like the type inferred by referenceFromUrl, but
+// also recognizes links to CVEs and GHSAs at NVD, MITRE and GitHub
+// as advisories.
+func likelyReferenceType(url string) osv.ReferenceType {
+	if linkedID(url) != "" {
+		return osv.ReferenceTypeAdvisory
+	}
+	return referenceFromUrl(url).Type
+}
+
+// ReferencesByType returns the references of r with type t,
+// in the order in which they appear in r.
+func (r *Report) ReferencesByType(t osv.ReferenceType) []*Reference {
+	var refs []*Reference
+	for _, ref := range r.References {
+		if ref.Type == t {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// AdvisoryReference returns the first ADVISORY reference of r,
+// or nil if r has no ADVISORY reference.
+func (r *Report) AdvisoryReference() *Reference {
+	if refs := r.ReferencesByType(osv.ReferenceTypeAdvisory); len(refs) > 0 {
+		return refs[0]
+	}
+	return nil
+}
@@ -23,6 +23,7 @@ import (
 	"golang.org/x/vulndb/internal/ghsa"
 	"golang.org/x/vulndb/internal/osv"
 	"golang.org/x/vulndb/internal/proxy"
+	"golang.org/x/vulndb/internal/stdlib"
 	"gopkg.in/yaml.v3"
 )
 
@@ -39,6 +40,9 @@ type UnsupportedVersion struct {
 type Module struct {
 	Module   string         `yaml:",omitempty"`
 	Versions []VersionRange `yaml:",omitempty"`
+	// AllVersions indicates that every version of the module is
+	// intentionally treated as vulnerable, so no versions are listed.
+	AllVersions bool `yaml:"all_versions,omitempty"`
 	// Version types that exist in OSV, but we don't support.
 	// These may be added when automatically creating a report,
 	// but must be deleted in order to pass lint checks.
@@ -49,6 +53,9 @@ type Module struct {
 	// In general, we want to use the most recent vulnerable version of
 	// the package. Determining this programmatically is difficult, especially
 	// for packages without tagged versions, so we specify it manually here.
+	//
+	// Setting VulnerableAt means that the symbols of the module's packages
+	// can be verified; each package must set either this or SkipFix.
 	VulnerableAt string `yaml:"vulnerable_at,omitempty"`
 	// Additional list of module@version to require when performing static analysis.
 	// It is rare that we need to specify this.
@@ -66,7 +73,13 @@ type Package struct {
 	// or other technique.
 	DerivedSymbols []string `yaml:"derived_symbols,omitempty"`
 	// Reason the package is already considered fixed and should not be automatically updated.
+	// Setting SkipFix means that the package's symbols can't (or won't) be
+	// verified, so it should not be combined with a VulnerableAt version that
+	// no other package of the module uses.
 	SkipFix string `yaml:"skip_fix,omitempty"`
+	// WholePackage indicates that the whole package is intentionally
+	// treated as vulnerable, so no symbols are listed.
+	WholePackage bool `yaml:"whole_package,omitempty"`
 }
 
 type CVEMeta struct {
@@ -134,6 +147,15 @@ func (r *Reference) UnmarshalYAML(n *yaml.Node) error {
 	return nil
 }
 
+// DatabaseSpecific contains Go-specific information about a report.
+//
+// The database_specific field of the report's OSV entry also contains
+// the URL of the report, which is derived from the report ID.
+type DatabaseSpecific struct {
+	// ReviewStatus is the review status of the report.
+	ReviewStatus osv.ReviewStatus `yaml:"review_status,omitempty"`
+}
+
 // A Note is a note about the report.
 // May be typed or untyped (with Type left blank).
 type Note struct {
@@ -216,11 +238,83 @@ type Report struct {
 	// to fill in the ID string.
 	CVEMetadata *CVEMeta `yaml:"cve_metadata,omitempty"`
 
+	// DatabaseSpecific contains Go-specific information that is included
+	// in the database_specific field of the OSV entry.
+	DatabaseSpecific *DatabaseSpecific `yaml:"database_specific,omitempty"`
+
 	// Notes about the report. This field is ignored when creating
 	// OSV and CVE records. It can be used to document decisions made when
 	// creating the report, outstanding issues, or anything else worth
 	// mentioning.
 	Notes []*Note `yaml:",omitempty"`
+
+	// LintIgnore lists the codes of lint warnings that are known not to
+	// apply to the report, and are not reported (see LintIgnoreCodes).
+	// Errors can't be ignored.
+	LintIgnore []string `yaml:"lint_ignore,omitempty"`
+
+	// SchemaVersion is the schema version declared by the file that the
+	// report was read from (see CurrentSchemaVersion), or 0 if the file
+	// did not declare one. It is not written by Write, which always uses
+	// the current format.
+	SchemaVersion int `yaml:"-"`
+}
+
+// Clone returns a deep copy of r. Modifying the copy, including any of
+// its nested modules, packages, references, notes or CVE metadata,
+// does not affect r.
+func (r *Report) Clone() *Report {
+	c := *r
+	if r.Withdrawn != nil {
+		w := *r.Withdrawn
+		c.Withdrawn = &w
+	}
+	c.Modules = nil
+	for _, m := range r.Modules {
+		c.Modules = append(c.Modules, m.clone())
+	}
+	c.CVEs = slices.Clone(r.CVEs)
+	c.GHSAs = slices.Clone(r.GHSAs)
+	c.Related = slices.Clone(r.Related)
+	c.Credits = slices.Clone(r.Credits)
+	c.References = nil
+	for _, ref := range r.References {
+		ref := *ref
+		c.References = append(c.References, &ref)
+	}
+	if r.CVEMetadata != nil {
+		cm := *r.CVEMetadata
+		cm.References = slices.Clone(r.CVEMetadata.References)
+		c.CVEMetadata = &cm
+	}
+	if r.DatabaseSpecific != nil {
+		ds := *r.DatabaseSpecific
+		c.DatabaseSpecific = &ds
+	}
+	c.Notes = nil
+	for _, n := range r.Notes {
+		n := *n
+		c.Notes = append(c.Notes, &n)
+	}
+	c.LintIgnore = slices.Clone(r.LintIgnore)
+	return &c
+}
+
+func (m *Module) clone() *Module {
+	c := *m
+	c.Versions = slices.Clone(m.Versions)
+	c.UnsupportedVersions = slices.Clone(m.UnsupportedVersions)
+	c.VulnerableAtRequires = slices.Clone(m.VulnerableAtRequires)
+	c.Packages = nil
+	for _, p := range m.Packages {
+		p := *p
+		p.GOOS = slices.Clone(p.GOOS)
+		p.GOARCH = slices.Clone(p.GOARCH)
+		p.Symbols = slices.Clone(p.Symbols)
+		p.DerivedSymbols = slices.Clone(p.DerivedSymbols)
+		c.Packages = append(c.Packages, &p)
+	}
+	return &c
 }
 
 // GoCVE returns the CVE assigned to this report by the Go CNA,
@@ -255,17 +349,14 @@ func (r *Report) AddAliases(aliases []string) (added int) {
 	}
 
 	for _, alias := range aliases {
-		switch {
-		case original[alias]:
+		if original[alias] {
 			continue
-		case ghsa.IsGHSA(alias):
-			r.GHSAs = append(r.GHSAs, alias)
-		case cveschema5.IsCVE(alias):
-			r.CVEs = append(r.CVEs, alias)
-		default:
-			continue // skip aliases that are not CVEs or GHSAs
 		}
-		added++
+		// Skip aliases that are not CVEs or GHSAs.
+		if err := r.addAlias(alias); err == nil {
+			original[alias] = true
+			added++
+		}
 	}
 
 	if added > 0 {
@@ -276,6 +367,149 @@ func (r *Report) AddAliases(aliases []string) (added int) {
 	return added
 }
 
+// AddAlias adds id to the CVEs or GHSAs of the report, according to its
+// format, keeping them sorted. It does nothing if id is already an alias
+// of the report (including as its cve_metadata.id), and returns an error
+// if id is neither a CVE nor a GHSA.
+func (r *Report) AddAlias(id string) error {
+	if slices.Contains(r.Aliases(), id) {
+		return nil
+	}
+	if err := r.addAlias(id); err != nil {
+		return err
+	}
+	slices.Sort(r.GHSAs)
+	slices.Sort(r.CVEs)
+	return nil
+}
+
+// addAlias appends id to the CVEs or GHSAs of the report, according to
+// its format, without checking whether it is already present.
+func (r *Report) addAlias(id string) error {
+	switch {
+	case ghsa.IsGHSA(id):
+		r.GHSAs = append(r.GHSAs, id)
+	case cveschema5.IsCVE(id):
+		r.CVEs = append(r.CVEs, id)
+	default:
+		return fmt.Errorf("%q is not a CVE or GHSA ID", id)
+	}
+	return nil
+}
+
+// AffectedPackages returns the paths of all the packages affected by
+// the report, across all modules, deduplicated and sorted.
+//
+// If a module lists no packages, or a package has an empty path
+// (as may happen for the standard library and toolchain modules),
+// the module path is used instead.
+func (r *Report) AffectedPackages() []string {
+	seen := make(map[string]bool)
+	var pkgs []string
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			pkgs = append(pkgs, path)
+		}
+	}
+	for _, m := range r.Modules {
+		if len(m.Packages) == 0 {
+			add(m.Module)
+			continue
+		}
+		for _, p := range m.Packages {
+			if p.Package == "" {
+				add(m.Module)
+				continue
+			}
+			add(p.Package)
+		}
+	}
+	slices.Sort(pkgs)
+	return pkgs
+}
+
+// Summarize returns a one-line, human-readable summary of the report,
+// for use in changelogs and review comments. For example:
+//
+//	GO-2023-0001: XSS in github.com/foo/bar (affects <1.2.3), CVE-2023-1234
+//
+// The affected versions are given for a single module, prefixed by
+// the module path if the summary doesn't mention it, and the standard
+// library and toolchain modules are named "Go" and "the Go toolchain".
+// For several modules, only their number is given. An excluded report
+// is summarized by its excluded reason and modules instead. Aliases are
+// omitted if there are none.
+func (r *Report) Summarize() string {
+	var b strings.Builder
+	b.WriteString(r.ID)
+	if r.IsExcluded() {
+		fmt.Fprintf(&b, ": excluded as %s", r.Excluded)
+		switch len(r.Modules) {
+		case 0:
+		case 1:
+			fmt.Fprintf(&b, " (%s)", r.Modules[0].Module)
+		default:
+			fmt.Fprintf(&b, " (%d modules)", len(r.Modules))
+		}
+	} else {
+		if r.Summary != "" {
+			fmt.Fprintf(&b, ": %s", r.Summary)
+		}
+		switch len(r.Modules) {
+		case 0:
+		case 1:
+			m := r.Modules[0]
+			name := m.Module
+			switch name {
+			case stdlib.ModulePath:
+				name = "Go"
+			case stdlib.ToolchainModulePath:
+				name = "the Go toolchain"
+			}
+			vs := m.summarizeVersions()
+			switch {
+			case !m.IsFirstParty() && strings.Contains(r.Summary, m.Module):
+				// The summary already names the module.
+			case vs == "":
+				vs = "all versions of " + name
+			default:
+				vs = name + " " + vs
+			}
+			if vs == "" {
+				vs = "all versions"
+			}
+			fmt.Fprintf(&b, " (affects %s)", vs)
+		default:
+			fmt.Fprintf(&b, " (affects %d modules)", len(r.Modules))
+		}
+	}
+	if aliases := r.Aliases(); len(aliases) > 0 {
+		fmt.Fprintf(&b, ", %s", strings.Join(aliases, ", "))
+	}
+	return b.String()
+}
+
+// summarizeVersions returns a short description of the versions of m
+// that are affected, such as ">=1.0.0 <1.2.3", or "" if all versions
+// are affected. Multiple ranges are separated by " or ".
+func (m *Module) summarizeVersions() string {
+	var ranges []string
+	for _, vr := range m.Versions {
+		var parts []string
+		if vr.Introduced != "" {
+			parts = append(parts, ">="+vr.Introduced)
+		}
+		if vr.Fixed != "" {
+			parts = append(parts, "<"+vr.Fixed)
+		}
+		if len(parts) > 0 {
+			ranges = append(ranges, strings.Join(parts, " "))
+		}
+	}
+	return strings.Join(ranges, " or ")
+}
+
 const (
 	NISTPrefix    = "https://nvd.nist.gov/vuln/detail/"
 	ghsaURLPrefix = "https://github.com/advisories/"
@@ -322,15 +556,9 @@ func Read(filename string) (_ *Report, err error) {
 		return nil, err
 	}
 	defer f.Close()
-	d := yaml.NewDecoder(f)
 	// Require that all fields in the file are in the struct.
 	// This corresponds to v2's UnmarshalStrict.
-	d.KnownFields(true)
-	var r Report
-	if err := d.Decode(&r); err != nil {
-		return nil, fmt.Errorf("yaml.Decode: %v", err)
-	}
-	return &r, nil
+	return decode(f, true)
 }
 
 // ReadAndLint reads a Report in YAML format from filename,
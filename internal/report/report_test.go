@@ -9,8 +9,10 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vulndb/internal/osv"
 )
 
 func TestRoundTrip(t *testing.T) {
@@ -129,6 +131,15 @@ func TestAddAliases(t *testing.T) {
 				GHSAs: []string{"GHSA-aaaa-bbbb-cccc"},
 			},
 		},
+		{
+			name:    "duplicate_input",
+			report:  &Report{},
+			aliases: []string{"CVE-2023-0001", "CVE-2023-0001", "GO-2023-0001"},
+			want:    1,
+			wantReport: &Report{
+				CVEs: []string{"CVE-2023-0001"},
+			},
+		},
 		{
 			name: "no_change",
 			report: &Report{
@@ -162,3 +173,327 @@ func TestAddAliases(t *testing.T) {
 		})
 	}
 }
+
+func TestAddAlias(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		report     *Report
+		id         string
+		wantErr    bool
+		wantReport *Report
+	}{
+		{
+			name:       "cve",
+			report:     &Report{CVEs: []string{"CVE-2023-0002"}},
+			id:         "CVE-2023-0001",
+			wantReport: &Report{CVEs: []string{"CVE-2023-0001", "CVE-2023-0002"}},
+		},
+		{
+			name:       "ghsa",
+			report:     &Report{CVEs: []string{"CVE-2023-0001"}},
+			id:         "GHSA-aaaa-bbbb-cccc",
+			wantReport: &Report{CVEs: []string{"CVE-2023-0001"}, GHSAs: []string{"GHSA-aaaa-bbbb-cccc"}},
+		},
+		{
+			name:       "duplicate",
+			report:     &Report{GHSAs: []string{"GHSA-aaaa-bbbb-cccc"}},
+			id:         "GHSA-aaaa-bbbb-cccc",
+			wantReport: &Report{GHSAs: []string{"GHSA-aaaa-bbbb-cccc"}},
+		},
+		{
+			name:       "cve metadata",
+			report:     &Report{CVEMetadata: &CVEMeta{ID: "CVE-2023-0001"}},
+			id:         "CVE-2023-0001",
+			wantReport: &Report{CVEMetadata: &CVEMeta{ID: "CVE-2023-0001"}},
+		},
+		{
+			name:       "malformed cve",
+			report:     &Report{},
+			id:         "CVE-2023-1",
+			wantErr:    true,
+			wantReport: &Report{},
+		},
+		{
+			name:       "malformed ghsa",
+			report:     &Report{},
+			id:         "GHSA-aaaa-bbbb",
+			wantErr:    true,
+			wantReport: &Report{},
+		},
+		{
+			name:       "go id",
+			report:     &Report{},
+			id:         "GO-2023-0001",
+			wantErr:    true,
+			wantReport: &Report{},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.report.AddAlias(test.id)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Errorf("AddAlias(%q) = %v, want error: %t", test.id, err, test.wantErr)
+			}
+			if diff := cmp.Diff(test.wantReport, test.report); diff != "" {
+				t.Errorf("AddAlias(%q) report mismatch: (-want, +got):\n%s", test.id, diff)
+			}
+		})
+	}
+}
+
+func TestAffectedPackages(t *testing.T) {
+	tests := []struct {
+		name   string
+		report *Report
+		want   []string
+	}{
+		{
+			name:   "no modules",
+			report: &Report{},
+			want:   nil,
+		},
+		{
+			name: "dedupe and sort",
+			report: &Report{
+				Modules: []*Module{
+					{
+						Module: "example.com/b",
+						Packages: []*Package{
+							{Package: "example.com/b/y"},
+							{Package: "example.com/b/x"},
+						},
+					},
+					{
+						Module: "example.com/a",
+						Packages: []*Package{
+							{Package: "example.com/a"},
+							{Package: "example.com/b/x"},
+						},
+					},
+				},
+			},
+			want: []string{"example.com/a", "example.com/b/x", "example.com/b/y"},
+		},
+		{
+			name: "module without packages",
+			report: &Report{
+				Modules: []*Module{
+					{Module: "std", Packages: []*Package{{Package: "net/http"}}},
+					{Module: "cmd"},
+					{Module: "example.com/m", Packages: []*Package{{}}},
+				},
+			},
+			want: []string{"cmd", "example.com/m", "net/http"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.report.AffectedPackages()
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("AffectedPackages() mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name   string
+		report *Report
+		want   string
+	}{
+		{
+			name: "third party",
+			report: &Report{
+				ID:      "GO-2023-0001",
+				Summary: "XSS in github.com/foo/bar",
+				Modules: []*Module{{
+					Module:   "github.com/foo/bar",
+					Versions: []VersionRange{{Fixed: "1.2.3"}},
+				}},
+				CVEs: []string{"CVE-2023-1234"},
+			},
+			want: "GO-2023-0001: XSS in github.com/foo/bar (affects <1.2.3), CVE-2023-1234",
+		},
+		{
+			name: "module not in summary, multiple ranges and aliases",
+			report: &Report{
+				ID:      "GO-2023-0002",
+				Summary: "Denial of service",
+				Modules: []*Module{{
+					Module: "github.com/foo/bar",
+					Versions: []VersionRange{
+						{Introduced: "1.0.0", Fixed: "1.1.5"},
+						{Introduced: "1.2.0", Fixed: "1.2.3"},
+					},
+				}},
+				CVEs:  []string{"CVE-2023-1234"},
+				GHSAs: []string{"GHSA-xxxx-yyyy-zzzz"},
+			},
+			want: "GO-2023-0002: Denial of service (affects github.com/foo/bar >=1.0.0 <1.1.5 or >=1.2.0 <1.2.3), CVE-2023-1234, GHSA-xxxx-yyyy-zzzz",
+		},
+		{
+			name: "stdlib",
+			report: &Report{
+				ID:      "GO-2023-0003",
+				Summary: "Panic in net/http",
+				Modules: []*Module{{
+					Module:   "std",
+					Versions: []VersionRange{{Fixed: "1.20.1"}},
+				}},
+				CVEMetadata: &CVEMeta{ID: "CVE-2023-5678"},
+			},
+			want: "GO-2023-0003: Panic in net/http (affects Go <1.20.1), CVE-2023-5678",
+		},
+		{
+			name: "multiple modules, no versions or aliases",
+			report: &Report{
+				ID:      "GO-2023-0004",
+				Summary: "Code injection in example.com/a",
+				Modules: []*Module{{Module: "example.com/a"}, {Module: "example.com/b"}},
+			},
+			want: "GO-2023-0004: Code injection in example.com/a (affects 2 modules)",
+		},
+		{
+			name: "all versions",
+			report: &Report{
+				ID:      "GO-2023-0005",
+				Summary: "Path traversal in cmd/go",
+				Modules: []*Module{{Module: "cmd"}},
+			},
+			want: "GO-2023-0005: Path traversal in cmd/go (affects all versions of the Go toolchain)",
+		},
+		{
+			name: "all versions, module in summary",
+			report: &Report{
+				ID:      "GO-2023-0007",
+				Summary: "Path traversal in github.com/foo/bar",
+				Modules: []*Module{{Module: "github.com/foo/bar"}},
+			},
+			want: "GO-2023-0007: Path traversal in github.com/foo/bar (affects all versions)",
+		},
+		{
+			name: "excluded",
+			report: &Report{
+				ID:       "GO-2023-0006",
+				Excluded: "NOT_IMPORTABLE",
+				Modules:  []*Module{{Module: "github.com/foo/bar"}},
+				GHSAs:    []string{"GHSA-xxxx-yyyy-zzzz"},
+			},
+			want: "GO-2023-0006: excluded as NOT_IMPORTABLE (github.com/foo/bar), GHSA-xxxx-yyyy-zzzz",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.report.Summarize(); got != test.want {
+				t.Errorf("Summarize() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestClone(t *testing.T) {
+	newReport := func() *Report {
+		withdrawn := time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC)
+		return &Report{
+			ID: "GO-0000-0000",
+			Modules: []*Module{{
+				Module:               "example.com/m",
+				Versions:             []VersionRange{{Introduced: "1.0.0", Fixed: "1.2.0"}},
+				UnsupportedVersions:  []UnsupportedVersion{{Version: "1.1.x", Type: "unknown"}},
+				VulnerableAt:         "1.1.0",
+				VulnerableAtRequires: []string{"example.com/a@v1.0.0"},
+				Packages: []*Package{{
+					Package:        "example.com/m/p",
+					GOOS:           []string{"linux"},
+					GOARCH:         []string{"amd64"},
+					Symbols:        []string{"F"},
+					DerivedSymbols: []string{"G"},
+				}},
+			}},
+			Summary:     "a summary",
+			Description: "a description",
+			Published:   time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			Withdrawn:   &withdrawn,
+			CVEs:        []string{"CVE-0000-0001"},
+			GHSAs:       []string{"GHSA-aaaa-bbbb-cccc"},
+			Related:     []string{"CVE-0000-0002"},
+			Credits:     []string{"A Person"},
+			References:  []*Reference{{Type: "WEB", URL: "https://example.com"}},
+			CVEMetadata: &CVEMeta{
+				ID:         "CVE-0000-0003",
+				CWE:        "CWE-000",
+				References: []string{"https://example.com/cve"},
+			},
+			DatabaseSpecific: &DatabaseSpecific{ReviewStatus: osv.ReviewStatusReviewed},
+			Notes:            []*Note{{Body: "a note", Type: NoteTypeLint}},
+			LintIgnore:       []string{"fix-host"},
+		}
+	}
+
+	orig := newReport()
+	clone := orig.Clone()
+	if diff := cmp.Diff(orig, clone); diff != "" {
+		t.Fatalf("Clone() mismatch (-orig, +clone):\n%s", diff)
+	}
+
+	// Mutate everything reachable from the clone.
+	*clone.Withdrawn = time.Time{}
+	m := clone.Modules[0]
+	m.Module = "example.com/other"
+	m.Versions[0].Fixed = "2.0.0"
+	m.UnsupportedVersions[0].Version = "x"
+	m.VulnerableAtRequires[0] = "x"
+	p := m.Packages[0]
+	p.Package = "x"
+	p.GOOS[0] = "x"
+	p.GOARCH[0] = "x"
+	p.Symbols[0] = "x"
+	p.DerivedSymbols[0] = "x"
+	m.Packages = append(m.Packages, &Package{})
+	clone.Modules = append(clone.Modules, &Module{})
+	clone.CVEs[0] = "x"
+	clone.GHSAs[0] = "x"
+	clone.Related[0] = "x"
+	clone.Credits[0] = "x"
+	clone.References[0].URL = "x"
+	clone.CVEMetadata.ID = "x"
+	clone.CVEMetadata.References[0] = "x"
+	clone.DatabaseSpecific.ReviewStatus = osv.ReviewStatusUnreviewed
+	clone.Notes[0].Body = "x"
+	clone.LintIgnore[0] = "x"
+
+	if diff := cmp.Diff(newReport(), orig); diff != "" {
+		t.Errorf("original modified by mutating clone (-want, +got):\n%s", diff)
+	}
+}
+
+func TestReferencesByType(t *testing.T) {
+	fix1 := &Reference{Type: osv.ReferenceTypeFix, URL: "https://example.com/fix1"}
+	fix2 := &Reference{Type: osv.ReferenceTypeFix, URL: "https://example.com/fix2"}
+	adv := &Reference{Type: osv.ReferenceTypeAdvisory, URL: "https://example.com/advisory"}
+	web := &Reference{Type: osv.ReferenceTypeWeb, URL: "https://example.com/web"}
+	r := &Report{References: []*Reference{fix1, web, adv, fix2}}
+
+	for _, test := range []struct {
+		typ  osv.ReferenceType
+		want []*Reference
+	}{
+		{osv.ReferenceTypeFix, []*Reference{fix1, fix2}},
+		{osv.ReferenceTypeAdvisory, []*Reference{adv}},
+		{osv.ReferenceTypeReport, nil},
+	} {
+		if diff := cmp.Diff(test.want, r.ReferencesByType(test.typ)); diff != "" {
+			t.Errorf("ReferencesByType(%s) mismatch (-want, +got):\n%s", test.typ, diff)
+		}
+	}
+
+	if got := r.AdvisoryReference(); got != adv {
+		t.Errorf("AdvisoryReference() = %v, want %v", got, adv)
+	}
+	r = &Report{References: []*Reference{fix1, web}}
+	if got := r.AdvisoryReference(); got != nil {
+		t.Errorf("AdvisoryReference() = %v, want nil", got)
+	}
+}
@@ -6,13 +6,13 @@ package report
 
 import (
 	"path"
+	"strings"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"golang.org/x/exp/slices"
 	"golang.org/x/vulndb/internal/derrors"
 	"golang.org/x/vulndb/internal/gitrepo"
-	"gopkg.in/yaml.v3"
 )
 
 var (
@@ -45,8 +45,8 @@ func All(repo *git.Repository) (byIssue map[int]*Report, byFile map[string]*Repo
 		if err != nil {
 			return err
 		}
-		var r Report
-		if err := yaml.Unmarshal([]byte(content), &r); err != nil {
+		r, err := decode(strings.NewReader(content), false)
+		if err != nil {
 			return err
 		}
 
@@ -55,8 +55,8 @@ func All(repo *git.Repository) (byIssue map[int]*Report, byFile map[string]*Repo
 			return err
 		}
 
-		byFile[f.Name] = &r
-		byIssue[iss] = &r
+		byFile[f.Name] = r
+		byIssue[iss] = r
 
 		return nil
 	}); err != nil {
@@ -113,8 +113,8 @@ func Aliases(repo *git.Repository) (_ []string, err error) {
 		if err != nil {
 			return err
 		}
-		var r Report
-		if err := yaml.Unmarshal([]byte(content), &r); err != nil {
+		r, err := decode(strings.NewReader(content), false)
+		if err != nil {
 			return err
 		}
 
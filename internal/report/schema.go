@@ -0,0 +1,108 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package report
+
+import (
+	"fmt"
+	"io"
+
+	"golang.org/x/vulndb/internal/derrors"
+	"golang.org/x/vulndb/internal/osv"
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentSchemaVersion is the version of the report format described
+// by the Report type.
+//
+// Files in the current format do not need to declare a schema_version.
+// Files in an older format must declare it, so that they can be
+// migrated when they are read. The known versions are:
+//
+//   - 1: references are given by a "links" mapping with the keys
+//     "pr" and "commit" (FIX references) and "context" (WEB references),
+//     instead of the "references" list.
+//   - 2: the current format.
+const CurrentSchemaVersion = 2
+
+// legacyLinks is the "links" field of schema version 1.
+type legacyLinks struct {
+	PR      string   `yaml:"pr,omitempty"`
+	Commit  string   `yaml:"commit,omitempty"`
+	Context []string `yaml:"context,omitempty"`
+}
+
+// versionedReport is the union of the fields of all known schema versions.
+type versionedReport struct {
+	SchemaVersion int `yaml:"schema_version,omitempty"`
+	Report        `yaml:",inline"`
+	Links         *legacyLinks `yaml:"links,omitempty"`
+}
+
+// decode decodes a report in YAML format from r, migrating it from
+// its declared schema version to the current one.
+// If strict is true, all fields in the YAML must be known.
+func decode(r io.Reader, strict bool) (*Report, error) {
+	d := yaml.NewDecoder(r)
+	d.KnownFields(strict)
+	var vr versionedReport
+	if err := d.Decode(&vr); err != nil {
+		return nil, fmt.Errorf("yaml.Decode: %v", err)
+	}
+	if err := vr.migrate(); err != nil {
+		return nil, err
+	}
+	return &vr.Report, nil
+}
+
+// migrate converts vr to the current schema version, and records
+// the declared version in vr.Report.SchemaVersion.
+func (vr *versionedReport) migrate() error {
+	switch v := vr.SchemaVersion; {
+	case v < 0 || v > CurrentSchemaVersion:
+		return fmt.Errorf("unsupported schema_version %d (current: %d)", v, CurrentSchemaVersion)
+	case v != 1 && vr.Links != nil:
+		return fmt.Errorf("field links is only allowed with schema_version 1 (use references instead)")
+	}
+	if vr.Links != nil {
+		// Schema version 1 to 2.
+		var refs []*Reference
+		for _, u := range []string{vr.Links.PR, vr.Links.Commit} {
+			if u != "" {
+				refs = append(refs, &Reference{Type: osv.ReferenceTypeFix, URL: u})
+			}
+		}
+		for _, u := range vr.Links.Context {
+			refs = append(refs, &Reference{Type: osv.ReferenceTypeWeb, URL: u})
+		}
+		vr.References = append(refs, vr.References...)
+	}
+	vr.Report.SchemaVersion = vr.SchemaVersion
+	return nil
+}
+
+// IsDeprecatedSchema reports whether r was read from a file in a
+// deprecated schema version.
+func (r *Report) IsDeprecatedSchema() bool {
+	return r.SchemaVersion != 0 && r.SchemaVersion < CurrentSchemaVersion
+}
+
+// MigrateReport rewrites the report in filename in the current format,
+// if it uses a deprecated schema version. It reports whether the file
+// was rewritten.
+func MigrateReport(filename string) (migrated bool, err error) {
+	defer derrors.Wrap(&err, "MigrateReport(%q)", filename)
+
+	r, err := Read(filename)
+	if err != nil {
+		return false, err
+	}
+	if !r.IsDeprecatedSchema() {
+		return false, nil
+	}
+	if err := r.Write(filename); err != nil {
+		return false, err
+	}
+	return true, nil
+}
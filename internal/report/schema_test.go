@@ -0,0 +1,148 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vulndb/internal/osv"
+)
+
+func TestDecodeSchemaVersions(t *testing.T) {
+	for _, test := range []struct {
+		desc    string
+		in      string
+		want    *Report
+		wantErr string
+	}{
+		{
+			desc: "current, undeclared",
+			in: `id: GO-0000-0000
+references:
+  - fix: https://go.dev/cl/12345
+`,
+			want: &Report{
+				ID: "GO-0000-0000",
+				References: []*Reference{
+					{Type: osv.ReferenceTypeFix, URL: "https://go.dev/cl/12345"},
+				},
+			},
+		},
+		{
+			desc: "current, declared",
+			in: `schema_version: 2
+id: GO-0000-0000
+`,
+			want: &Report{ID: "GO-0000-0000", SchemaVersion: 2},
+		},
+		{
+			desc: "version 1",
+			in: `schema_version: 1
+id: GO-0000-0000
+links:
+  pr: https://github.com/a/b/pull/1
+  commit: https://github.com/a/b/commit/123
+  context:
+    - https://example.com/1
+    - https://example.com/2
+references:
+  - report: https://github.com/a/b/issues/2
+`,
+			want: &Report{
+				ID: "GO-0000-0000",
+				References: []*Reference{
+					{Type: osv.ReferenceTypeFix, URL: "https://github.com/a/b/pull/1"},
+					{Type: osv.ReferenceTypeFix, URL: "https://github.com/a/b/commit/123"},
+					{Type: osv.ReferenceTypeWeb, URL: "https://example.com/1"},
+					{Type: osv.ReferenceTypeWeb, URL: "https://example.com/2"},
+					{Type: osv.ReferenceTypeReport, URL: "https://github.com/a/b/issues/2"},
+				},
+				SchemaVersion: 1,
+			},
+		},
+		{
+			desc: "links without version",
+			in: `id: GO-0000-0000
+links:
+  pr: https://github.com/a/b/pull/1
+`,
+			wantErr: "field links is only allowed with schema_version 1",
+		},
+		{
+			desc: "unknown version",
+			in: `schema_version: 3
+id: GO-0000-0000
+`,
+			wantErr: "unsupported schema_version 3",
+		},
+		{
+			desc: "unknown field",
+			in: `id: GO-0000-0000
+not_a_field: true
+`,
+			wantErr: "not_a_field not found",
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			got, err := decode(strings.NewReader(test.in), true)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("decode() error = %v, want error containing %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMigrateReport(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "GO-0000-0000.yaml")
+	if err := os.WriteFile(filename, []byte(`schema_version: 1
+id: GO-0000-0000
+links:
+  pr: https://github.com/a/b/pull/1
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	migrated, err := MigrateReport(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !migrated {
+		t.Error("first MigrateReport: migrated = false, want true")
+	}
+	got, err := Read(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Report{
+		ID: "GO-0000-0000",
+		References: []*Reference{
+			{Type: osv.ReferenceTypeFix, URL: "https://github.com/a/b/pull/1"},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("migrated report mismatch (-want, +got):\n%s", diff)
+	}
+
+	migrated, err = MigrateReport(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if migrated {
+		t.Error("second MigrateReport: migrated = true, want false")
+	}
+}
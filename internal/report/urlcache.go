@@ -0,0 +1,67 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package report
+
+import (
+	"net/http"
+	"sync"
+
+	"golang.org/x/vulndb/internal/derrors"
+)
+
+// A URLCache records the HTTP status of URLs, so that online lint
+// checks request each URL at most once, even across reports.
+// It is safe for concurrent use.
+type URLCache struct {
+	client *http.Client
+
+	mu       sync.Mutex
+	statuses map[string]int
+}
+
+// NewURLCache returns a URLCache that makes requests with client.
+// A nil client means http.DefaultClient.
+func NewURLCache(client *http.Client) *URLCache {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &URLCache{client: client, statuses: make(map[string]int)}
+}
+
+// status returns the HTTP status code of a HEAD request for url,
+// following redirects.
+//
+// Only 2xx and 404 and 410 statuses are cached. Other statuses
+// (for example, rate limiting or server errors), and errors making
+// the request, may be transient, so they are returned but not cached.
+func (c *URLCache) status(url string) (_ int, err error) {
+	defer derrors.Wrap(&err, "status(%q)", url)
+
+	c.mu.Lock()
+	s, ok := c.statuses[url]
+	c.mu.Unlock()
+	if ok {
+		return s, nil
+	}
+
+	resp, err := c.client.Head(url)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	s = resp.StatusCode
+	if isDefinitive(s) {
+		c.mu.Lock()
+		c.statuses[url] = s
+		c.mu.Unlock()
+	}
+	return s, nil
+}
+
+// isDefinitive reports whether the HTTP status s reliably indicates
+// whether a resource exists.
+func isDefinitive(s int) bool {
+	return (s >= 200 && s < 300) || s == http.StatusNotFound || s == http.StatusGone
+}
@@ -0,0 +1,83 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package report
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vulndb/internal/osv"
+)
+
+// fakeTransport responds to requests with the status in statuses
+// for the request URL, or fails if there is none. It records the
+// URLs requested.
+type fakeTransport struct {
+	statuses  map[string]int
+	requested []string
+}
+
+func (t *fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	u := req.URL.String()
+	t.requested = append(t.requested, u)
+	s, ok := t.statuses[u]
+	if !ok {
+		return nil, errors.New("connection reset")
+	}
+	return &http.Response{
+		StatusCode: s,
+		Body:       io.NopCloser(strings.NewReader("")),
+		Request:    req,
+	}, nil
+}
+
+func TestCheckReferenceRepos(t *testing.T) {
+	transport := &fakeTransport{statuses: map[string]int{
+		"https://github.com/owner/ok":      http.StatusOK,
+		"https://github.com/owner/deleted": http.StatusNotFound,
+		"https://github.com/owner/limited": http.StatusTooManyRequests,
+	}}
+	c := NewURLCache(&http.Client{Transport: transport})
+	r := validReport(func(r *Report) {
+		r.References = []*Reference{
+			{Type: osv.ReferenceTypeFix, URL: "https://github.com/owner/ok/commit/0123456789abcdef0123456789abcdef01234567"},
+			{Type: osv.ReferenceTypeWeb, URL: "https://github.com/owner/ok/issues/1"},
+			{Type: osv.ReferenceTypeFix, URL: "https://github.com/Owner/Deleted/pull/2"},
+			{Type: osv.ReferenceTypeWeb, URL: "https://github.com/owner/limited"},
+			{Type: osv.ReferenceTypeWeb, URL: "https://github.com/owner/unreachable"},
+			{Type: osv.ReferenceTypeAdvisory, URL: "https://github.com/advisories/GHSA-xxxx-yyyy-zzzz"},
+			{Type: osv.ReferenceTypeWeb, URL: "https://gitlab.com/owner/deleted"},
+		}
+	})
+
+	for i := 0; i < 2; i++ {
+		var got []string
+		r.checkReferenceRepos(c, func(_, iss string) {
+			got = append(got, iss)
+		})
+		want := []string{"reference repo github.com/owner/deleted is unavailable (404)"}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("run %d: mismatch (-want, +got):\n%s", i, diff)
+		}
+	}
+
+	// Definitive results are requested once; transient failures are
+	// retried.
+	want := []string{
+		"https://github.com/owner/ok",
+		"https://github.com/owner/deleted",
+		"https://github.com/owner/limited",
+		"https://github.com/owner/unreachable",
+		"https://github.com/owner/limited",
+		"https://github.com/owner/unreachable",
+	}
+	if diff := cmp.Diff(want, transport.requested); diff != "" {
+		t.Errorf("requests mismatch (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package symbols
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/vulndb/internal/derrors"
+	"golang.org/x/vulndb/internal/report"
+)
+
+// A Cache is an on-disk cache of the symbols derived by ExportedSymbols
+// and ExportedResult.
+//
+// Entries are keyed by everything that can affect the result: the module
+// path, vulnerable_at version and vulnerable_at_requires, the package,
+// the symbols of every package in the module, the build flags,
+// environment, AssumeDynamicCalls and Revision options given in Options,
+// the version of the go command that loads the packages, and the Go
+// version that this program was built with, which determines the SSA
+// construction.
+type Cache struct {
+	dir string
+}
+
+// NewCache returns a cache that stores its entries in dir,
+// creating dir if necessary.
+func NewCache(dir string) (_ *Cache, err error) {
+	defer derrors.Wrap(&err, "NewCache(%q)", dir)
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Cache{dir: dir}, nil
+}
+
+// Clear removes all entries from the cache.
+func (c *Cache) Clear() (err error) {
+	defer derrors.Wrap(&err, "Clear(%q)", c.dir)
+
+	files, err := filepath.Glob(filepath.Join(c.dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err := os.Remove(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cacheKey returns the key for the symbols derived for package p
+// of module m with the given options, by the go command of version
+// buildGoVersion.
+func cacheKey(m *report.Module, p *report.Package, opts *Options, buildGoVersion string) string {
+	type pkgSymbols struct {
+		Package string
+		Symbols []string
+	}
+	key := struct {
+		Module               string
+		VulnerableAt         string
+		VulnerableAtRequires []string
+		Package              string
+		// The symbols of all the packages in the module,
+		// because they are all used to compute vulnerable entry points.
+		// Derived symbols are omitted: they are reachable from these
+		// by construction.
+		Packages           []pkgSymbols
+		BuildFlags         []string
+		Env                []string
+		AssumeDynamicCalls bool
+		Revision           string `json:",omitempty"`
+		BuildGoVersion     string
+		GoVersion          string
+	}{
+		Module:               m.Module,
+		VulnerableAt:         m.VulnerableAt,
+		VulnerableAtRequires: m.VulnerableAtRequires,
+		Package:              p.Package,
+		BuildFlags:           opts.BuildFlags,
+		Env:                  opts.Env,
+		AssumeDynamicCalls:   opts.AssumeDynamicCalls,
+		Revision:             opts.Revision,
+		BuildGoVersion:       buildGoVersion,
+		GoVersion:            runtime.Version(),
+	}
+	for _, mp := range m.Packages {
+		syms := slices.Clone(mp.Symbols)
+		slices.Sort(syms)
+		key.Packages = append(key.Packages, pkgSymbols{mp.Package, syms})
+	}
+	sort.Slice(key.Packages, func(i, j int) bool {
+		return key.Packages[i].Package < key.Packages[j].Package
+	})
+	b, err := json.Marshal(key)
+	if err != nil {
+		// This can't happen: all the fields are strings or bools.
+		panic(err)
+	}
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+func (c *Cache) filename(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+// get returns the cached result for key, and whether it was found.
+func (c *Cache) get(key string) (*Result, bool) {
+	b, err := os.ReadFile(c.filename(key))
+	if err != nil {
+		return nil, false
+	}
+	var res Result
+	// Entries written before results were cached hold only a list
+	// of symbols, so they fail to decode and are treated as misses.
+	if err := json.Unmarshal(b, &res); err != nil {
+		return nil, false
+	}
+	return &res, true
+}
+
+// put stores res in the cache under key.
+func (c *Cache) put(key string, res *Result) (err error) {
+	defer derrors.Wrap(&err, "put(%q)", key)
+
+	b, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first so that concurrent readers
+	// never see a partial entry.
+	f, err := os.CreateTemp(c.dir, key+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), c.filename(key))
+}
@@ -0,0 +1,183 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package symbols
+
+import (
+	"log"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vulndb/internal/report"
+)
+
+func TestCache(t *testing.T) {
+	c, err := NewCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &report.Module{
+		Module:       "example.com/m",
+		VulnerableAt: "1.0.0",
+		Packages: []*report.Package{
+			{Package: "example.com/m/p", Symbols: []string{"A", "B"}},
+		},
+	}
+	p := m.Packages[0]
+	goVersion, err := buildGoVersion(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := cacheKey(m, p, &Options{}, goVersion)
+	if _, ok := c.get(key); ok {
+		t.Fatal("get on empty cache: got ok")
+	}
+
+	want := []*Symbol{{Name: "C"}, {Name: "D", Deprecated: true}}
+	res := &Result{Symbols: want, UnreachableVulnSymbols: []string{"B"}, BuildGoVersion: goVersion}
+	if err := c.put(key, res); err != nil {
+		t.Fatal(err)
+	}
+	gotRes, ok := c.get(key)
+	if !ok {
+		t.Fatal("get after put: not found")
+	}
+	if diff := cmp.Diff(res, gotRes); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	// A cache hit is returned without loading any packages.
+	gotRes, err = ExportedResult(m, p, &Options{Cache: c}, log.New(os.Stderr, "", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(res, gotRes); diff != "" {
+		t.Errorf("ExportedResult mismatch (-want, +got):\n%s", diff)
+	}
+
+	got, err := ExportedSymbols(m, p, &Options{Cache: c}, log.New(os.Stderr, "", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ExportedSymbols mismatch (-want, +got):\n%s", diff)
+	}
+
+	// Entries in the old format, a list of symbols, are misses.
+	if err := os.WriteFile(c.filename(key), []byte(`[{"Name":"C"}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.get(key); ok {
+		t.Error("get of old entry: got ok")
+	}
+
+	if err := c.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.get(key); ok {
+		t.Error("get after Clear: got ok")
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	mod := func(f func(m *report.Module)) *report.Module {
+		m := &report.Module{
+			Module:       "example.com/m",
+			VulnerableAt: "1.0.0",
+			Packages: []*report.Package{
+				{Package: "example.com/m/p", Symbols: []string{"A", "B"}},
+				{Package: "example.com/m/q", Symbols: []string{"C"}},
+			},
+		}
+		f(m)
+		return m
+	}
+	key := func(m *report.Module, opts *Options) string {
+		if opts == nil {
+			opts = &Options{}
+		}
+		return cacheKey(m, m.Packages[0], opts, "go1.21.0")
+	}
+	base := key(mod(func(*report.Module) {}), nil)
+
+	for _, test := range []struct {
+		desc     string
+		m        *report.Module
+		opts     *Options
+		wantSame bool
+	}{
+		{
+			desc: "symbol order",
+			m: mod(func(m *report.Module) {
+				m.Packages[0].Symbols = []string{"B", "A"}
+			}),
+			wantSame: true,
+		},
+		{
+			desc: "derived symbols",
+			m: mod(func(m *report.Module) {
+				m.Packages[0].DerivedSymbols = []string{"D"}
+			}),
+			wantSame: true,
+		},
+		{
+			desc: "vulnerable_at",
+			m: mod(func(m *report.Module) {
+				m.VulnerableAt = "1.0.1"
+			}),
+		},
+		{
+			desc: "vulnerable_at_requires",
+			m: mod(func(m *report.Module) {
+				m.VulnerableAtRequires = []string{"example.com/a@v1.0.0"}
+			}),
+		},
+		{
+			desc: "symbols of other package",
+			m: mod(func(m *report.Module) {
+				m.Packages[1].Symbols = []string{"C", "E"}
+			}),
+		},
+		{
+			desc: "cache options",
+			m:    mod(func(*report.Module) {}),
+			opts: &Options{Refresh: true},
+			// The cache options don't affect the derived symbols.
+			wantSame: true,
+		},
+		{
+			desc: "build flags",
+			m:    mod(func(*report.Module) {}),
+			opts: &Options{BuildFlags: []string{"-mod=mod"}},
+		},
+		{
+			desc: "env",
+			m:    mod(func(*report.Module) {}),
+			opts: &Options{Env: []string{"GOFLAGS=-mod=mod"}},
+		},
+		{
+			desc: "assume dynamic calls",
+			m:    mod(func(*report.Module) {}),
+			opts: &Options{AssumeDynamicCalls: true},
+		},
+		{
+			desc: "revision",
+			m:    mod(func(*report.Module) {}),
+			opts: &Options{Revision: "abcdef123456"},
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			if same := key(test.m, test.opts) == base; same != test.wantSame {
+				t.Errorf("same key = %t, want %t", same, test.wantSame)
+			}
+		})
+	}
+
+	// A different go command invalidates the entry.
+	m := mod(func(*report.Module) {})
+	if cacheKey(m, m.Packages[0], &Options{}, "go1.22.0") == base {
+		t.Error("go command version: same key, want different")
+	}
+}
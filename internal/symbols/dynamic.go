@@ -0,0 +1,96 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package symbols
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// dynamicCallReason returns a description of the first construct in pkg
+// that can call functions without the call appearing in the call graph,
+// or "" if there is none.
+//
+// The constructs are calls to reflect.Value.Call or CallSlice,
+// and //go:linkname directives.
+func dynamicCallReason(pkg *packages.Package) string {
+	pos := func(p token.Pos) string {
+		position := pkg.Fset.Position(p)
+		return fmt.Sprintf("%s:%d", filepath.Base(position.Filename), position.Line)
+	}
+	for _, f := range pkg.Syntax {
+		for _, cg := range f.Comments {
+			for _, c := range cg.List {
+				if strings.HasPrefix(c.Text, "//go:linkname ") {
+					return fmt.Sprintf("%s: uses //go:linkname", pos(c.Pos()))
+				}
+			}
+		}
+	}
+	for _, f := range pkg.Syntax {
+		var reason string
+		ast.Inspect(f, func(n ast.Node) bool {
+			if reason != "" {
+				return false
+			}
+			sel, ok := n.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			if fn, ok := pkg.TypesInfo.Uses[sel.Sel].(*types.Func); ok {
+				switch name := fn.FullName(); name {
+				case "(reflect.Value).Call", "(reflect.Value).CallSlice":
+					reason = fmt.Sprintf("%s: calls %s", pos(sel.Pos()), name)
+				}
+			}
+			return true
+		})
+		if reason != "" {
+			return reason
+		}
+	}
+	return ""
+}
+
+// allExportedFunctions returns the functions and methods of pkg that
+// can be called from other packages, keyed by symbol name, with the
+// given reason for considering them vulnerable.
+//
+// Methods with exported names are included even if their type is
+// unexported, because they may be called through an interface.
+func allExportedFunctions(pkg *packages.Package, reason string) map[string]*Symbol {
+	syms := make(map[string]*Symbol)
+	add := func(name string) {
+		syms[name] = &Symbol{Name: name, Reason: reason}
+	}
+	scope := pkg.Types.Scope()
+	for _, name := range scope.Names() {
+		switch obj := scope.Lookup(name).(type) {
+		case *types.Func:
+			if obj.Exported() {
+				add(name)
+			}
+		case *types.TypeName:
+			if obj.IsAlias() {
+				continue
+			}
+			mset := types.NewMethodSet(types.NewPointer(obj.Type()))
+			for i := 0; i < mset.Len(); i++ {
+				m := mset.At(i).Obj()
+				// Skip promoted methods, which belong to another type.
+				if len(mset.At(i).Index()) == 1 && m.Exported() {
+					add(name + "." + m.Name())
+				}
+			}
+		}
+	}
+	return syms
+}
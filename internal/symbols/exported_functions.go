@@ -6,79 +6,384 @@ package symbols
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"go/ast"
+	"go/token"
 	"go/types"
+	"io"
 	"log"
 	"os"
 	"os/exec"
+	"path"
+	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 
 	"golang.org/x/exp/slices"
+	"golang.org/x/mod/modfile"
 	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/go/ssa"
 	"golang.org/x/vulndb/internal/derrors"
 	"golang.org/x/vulndb/internal/osvutils"
 	"golang.org/x/vulndb/internal/report"
+	"golang.org/x/vulndb/internal/stdlib"
 	"golang.org/x/vulndb/internal/version"
 )
 
+// A Symbol is an exported vulnerable symbol derived by static analysis.
+type Symbol struct {
+	// Name is the name of the symbol, in the form used by
+	// report.Package.Symbols (e.g. "Func" or "Type.Method").
+	Name string
+	// Deprecated indicates that the declaration of the symbol
+	// has a "Deprecated:" comment.
+	Deprecated bool
+	// Reason, if non-empty, explains why the symbol is considered
+	// vulnerable although it was not found to reach a vulnerable
+	// symbol (see Options.AssumeDynamicCalls).
+	Reason string `json:",omitempty"`
+}
+
 // Exported returns a set of vulnerable symbols exported
 // by a package p from the module m.
+//
+// Exported returns no symbols if p has no symbols to derive from;
+// use ExportedResult to tell this apart from finding no new symbols.
 func Exported(m *report.Module, p *report.Package, errlog *log.Logger) (_ []string, err error) {
-	defer derrors.Wrap(&err, "Exported(%q, %q)", m.Module, p.Package)
+	syms, err := ExportedSymbols(m, p, nil, errlog)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, s := range syms {
+		names = append(names, s.Name)
+	}
+	return names, nil
+}
 
-	cleanup, err := changeToTempDir()
+// ExportedStrict is like Exported, but fails if any of the symbols
+// listed for p is not a function or method of the loaded package,
+// instead of logging the symbol to errlog and deriving symbols anyway.
+// The error lists all such symbols. No symbols are derived in that
+// case, since the package may not be the one the report describes.
+func ExportedStrict(m *report.Module, p *report.Package, errlog *log.Logger) (_ []string, err error) {
+	syms, err := ExportedSymbols(m, p, &Options{strict: true}, errlog)
 	if err != nil {
 		return nil, err
 	}
-	defer cleanup()
+	var names []string
+	for _, s := range syms {
+		names = append(names, s.Name)
+	}
+	return names, nil
+}
 
-	run := func(name string, arg ...string) error {
-		cmd := exec.Command(name, arg...)
-		out, err := cmd.CombinedOutput()
-		if err != nil {
-			errlog.Println(string(out))
+// Options configures ExportedSymbols.
+// A nil *Options is equivalent to the zero value.
+type Options struct {
+	// Cache, if non-nil, is used to store derived symbols
+	// across runs.
+	Cache *Cache
+	// Refresh causes existing cache entries to be ignored
+	// (and overwritten).
+	Refresh bool
+	// BuildFlags are additional flags for the go command used to load
+	// packages (see packages.Config.BuildFlags), for example
+	// "-mod=mod" for modules whose go.mod files are incomplete.
+	//
+	// If the flags (or GOFLAGS in Env) contain "-mod=vendor", the
+	// module's dependencies are vendored before loading packages.
+	BuildFlags []string
+	// Env contains additional environment variables, of the form
+	// "key=value", for all the go commands that are run, including
+	// those run to load packages. It can be used to configure module
+	// downloads without changing the environment of the process, for
+	// example to use a module mirror ("GOPROXY=https://mirror.example.com")
+	// and to skip checksum verification of private modules
+	// ("GONOSUMDB=example.com/private"). Later entries take
+	// precedence over earlier ones and over the process environment.
+	Env []string
+	// AssumeDynamicCalls causes all the exported functions and methods
+	// of a package to be considered vulnerable if the package calls
+	// reflect.Value.Call or uses //go:linkname, because the functions
+	// that these call can't be found by static analysis.
+	// The reason is given in Symbol.Reason and logged.
+	AssumeDynamicCalls bool
+	// Loader, if non-nil, replaces the default way of loading the
+	// package to derive symbols from, which builds a temporary module
+	// requiring the vulnerable version and runs the go command.
+	// It is intended for tests. The Cache is not used when Loader
+	// is set.
+	Loader PackageLoader
+	// DebugSSA, if non-nil, receives the SSA of the analyzed packages
+	// and of the functions on call paths to vulnerable symbols, in
+	// the text form of the ssa package. It is for debugging surprising
+	// derived symbols only; the output format may change. The Cache
+	// is not used when DebugSSA is set.
+	DebugSSA io.Writer
+	// Revision, if non-empty, is the version of the module to analyze
+	// instead of its vulnerable_at version, for triaging a
+	// vulnerability in unreleased code. It must be a pseudo-version
+	// (with no leading "v"), or the hash of a commit in the module's
+	// repository, which the go command resolves to a pseudo-version.
+	Revision string
+	// LocalDir, if non-empty, is the absolute path of a local checkout
+	// of the module to analyze instead of its vulnerable_at version.
+	// The module is required by means of a replace directive. Since a
+	// checkout has no version, the module is assumed to be affected.
+	// The Cache is not used when LocalDir is set.
+	//
+	// Revision and LocalDir can't both be set, and neither can be
+	// used with the standard library or toolchain.
+	LocalDir string
+
+	// strict causes listed symbols that are not in the package to be
+	// an error (see ExportedStrict).
+	strict bool
+}
+
+// checkSource checks that the Revision and LocalDir fields of o
+// are valid for module m.
+func (o *Options) checkSource(m *report.Module) error {
+	switch {
+	case o.Revision == "" && o.LocalDir == "":
+		return nil
+	case o.Revision != "" && o.LocalDir != "":
+		return errors.New("only one of Revision and LocalDir can be set")
+	case m.IsFirstParty():
+		return fmt.Errorf("a revision or local directory can't be used with module %s", m.Module)
+	case o.Revision != "":
+		if !version.IsPseudo(o.Revision) && !version.IsCommitHash(o.Revision) {
+			return fmt.Errorf("revision %q is not a pseudo-version or commit hash", o.Revision)
 		}
+		return nil
+	}
+	if !filepath.IsAbs(o.LocalDir) {
+		return fmt.Errorf("local directory %q is not an absolute path", o.LocalDir)
+	}
+	b, err := os.ReadFile(filepath.Join(o.LocalDir, "go.mod"))
+	if err != nil {
 		return err
 	}
+	if path := modfile.ModulePath(b); path != m.Module {
+		return fmt.Errorf("local directory %s contains module %q, not %s", o.LocalDir, path, m.Module)
+	}
+	return nil
+}
 
-	// This procedure was developed through trial and error finding a way
-	// to load symbols for GO-2023-1549, which has a dependency tree that
-	// includes go.mod files that reference v0.0.0 versions which do not exist.
-	//
-	// Create an empty go.mod.
-	if err := run("go", "mod", "init", "go.dev/_"); err != nil {
+// A PackageLoader loads packages to derive vulnerable symbols from.
+type PackageLoader interface {
+	// LoadPackage loads package p of module m at the vulnerable_at
+	// version of m, with enough information for constructing a call
+	// graph (see loadPackages).
+	LoadPackage(m *report.Module, p *report.Package, opts *Options, errlog *log.Logger) (*packages.Package, error)
+}
+
+// goLoader is the default PackageLoader, which loads packages
+// from a temporary module using the go command.
+type goLoader struct{}
+
+func (goLoader) LoadPackage(m *report.Module, p *report.Package, opts *Options, errlog *log.Logger) (*packages.Package, error) {
+	if err := opts.checkSource(m); err != nil {
 		return nil, err
 	}
-	if !m.IsFirstParty() {
-		// Require the module we're interested in at the vulnerable_at version.
-		if err := run("go", "mod", "edit", "-require", m.Module+"@v"+m.VulnerableAt); err != nil {
+	dir, cleanup, err := makeTempDir()
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	if err := initModule(dir, m, opts, errlog); err != nil {
+		return nil, err
+	}
+	if err := requirePackages(dir, m, []string{p.Package}, opts.Env, errlog); err != nil {
+		return nil, err
+	}
+	if opts.vendor() && !m.IsFirstParty() {
+		// The vendor directory can only be created once go mod tidy
+		// has resolved all the requirements.
+		if err := run(errlog, dir, opts.Env, "go", "mod", "vendor"); err != nil {
 			return nil, err
 		}
-		for _, req := range m.VulnerableAtRequires {
-			if err := run("go", "mod", "edit", "-require", req); err != nil {
-				return nil, err
-			}
+	}
+	return loadPackage(opts.packagesConfig(dir), p.Package)
+}
+
+// loader returns the PackageLoader selected by o.
+func (o *Options) loader() PackageLoader {
+	if o.Loader != nil {
+		return o.Loader
+	}
+	return goLoader{}
+}
+
+// packagesConfig returns the configuration for loading packages
+// in dir (or the current directory, if dir is "").
+func (o *Options) packagesConfig(dir string) *packages.Config {
+	cfg := &packages.Config{Dir: dir, BuildFlags: slices.Clone(o.BuildFlags)}
+	if len(o.Env) > 0 {
+		cfg.Env = append(os.Environ(), o.Env...)
+	}
+	return cfg
+}
+
+// vendor reports whether o selects the -mod=vendor build mode.
+func (o *Options) vendor() bool {
+	flags := slices.Clone(o.BuildFlags)
+	for _, e := range o.Env {
+		if strings.HasPrefix(e, "GOFLAGS=") {
+			flags = append(flags, strings.Fields(strings.TrimPrefix(e, "GOFLAGS="))...)
 		}
-		// Create a package that imports the package we're interested in.
-		var content bytes.Buffer
-		fmt.Fprintf(&content, "package p\n")
-		fmt.Fprintf(&content, "import _ %q\n", p.Package)
-		for _, req := range m.VulnerableAtRequires {
-			pkg, _, _ := strings.Cut(req, "@")
-			fmt.Fprintf(&content, "import _ %q", pkg)
+	}
+	mode := ""
+	for _, f := range flags {
+		if strings.HasPrefix(f, "-mod=") {
+			mode = strings.TrimPrefix(f, "-mod=") // the last -mod flag wins
 		}
-		if err := os.WriteFile("p.go", content.Bytes(), 0666); err != nil {
+	}
+	return mode == "vendor"
+}
+
+// ExportedSymbols is like Exported, but returns additional
+// information about each symbol. The symbols are sorted by name.
+func ExportedSymbols(m *report.Module, p *report.Package, opts *Options, errlog *log.Logger) (_ []*Symbol, err error) {
+	defer derrors.Wrap(&err, "ExportedSymbols(%q, %q)", m.Module, p.Package)
+
+	res, err := cachedResult(m, p, opts, errlog)
+	if err != nil {
+		return nil, err
+	}
+	return res.Symbols, nil
+}
+
+// cachedResult returns the result of exportedSymbols, using
+// the cache in opts if there is one.
+func cachedResult(m *report.Module, p *report.Package, opts *Options, errlog *log.Logger) (*Result, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	goVersion, err := buildGoVersion(opts.Env)
+	if err != nil {
+		return nil, err
+	}
+	derive := func() (*Result, error) {
+		res, err := exportedSymbols(m, p, opts, errlog)
+		if err != nil {
 			return nil, err
 		}
+		res.BuildGoVersion = goVersion
+		return res, nil
 	}
-	// Run go mod tidy.
-	if err := run("go", "mod", "tidy"); err != nil {
+	if opts.Cache == nil || opts.Loader != nil || opts.DebugSSA != nil || opts.LocalDir != "" || opts.strict {
+		return derive()
+	}
+	key := cacheKey(m, p, opts, goVersion)
+	if !opts.Refresh {
+		if res, ok := opts.Cache.get(key); ok {
+			return res, nil
+		}
+	}
+	res, err := derive()
+	if err != nil {
+		return nil, err
+	}
+	if err := opts.Cache.put(key, res); err != nil {
+		errlog.Println(err)
+	}
+	return res, nil
+}
+
+// A SkipReason explains why ExportedResult did not attempt to derive
+// any symbols.
+//
+// Packages of first-party modules (the standard library and the go
+// command) are never skipped: they have no module version to check,
+// and are analyzed as provided by the Go toolchain in use.
+type SkipReason int
+
+const (
+	// NotSkipped means that symbols were derived (although
+	// there may not have been any new ones).
+	NotSkipped SkipReason = iota
+	// NoInputSymbols means that the package has no symbols
+	// to derive other symbols from.
+	NoInputSymbols
+	// VersionNotAffected means that the version of the module
+	// that was loaded is not in the vulnerable range.
+	VersionNotAffected
+)
+
+func (r SkipReason) String() string {
+	switch r {
+	case NotSkipped:
+		return "not skipped"
+	case NoInputSymbols:
+		return "no input symbols"
+	case VersionNotAffected:
+		return "version not affected"
+	default:
+		return fmt.Sprintf("SkipReason(%d)", int(r))
+	}
+}
+
+// A Result is the result of ExportedResult.
+type Result struct {
+	// Symbols are the derived symbols, sorted by name.
+	Symbols []*Symbol
+	// UnreachableVulnSymbols are the symbols listed for the package
+	// that can't be reached from any of its exported functions and
+	// methods, sorted by name. They are dead code from the point of
+	// view of a caller outside the package, so reviewers may want to
+	// reconsider whether they belong in the report, or whether a
+	// skip_fix reason is warranted.
+	UnreachableVulnSymbols []string `json:",omitempty"`
+	// Positions maps the names of the derived symbols to the positions
+	// of their declarations, with filenames relative to the root of the
+	// module (or, for the standard library, GOROOT/src), so that
+	// reviewers can find them in the module's repository. Symbols
+	// without a position, such as the methods of embedded fields that
+	// are promoted to another type, are omitted.
+	Positions map[string]token.Position `json:",omitempty"`
+	// BuildGoVersion is the version of the go command that loaded
+	// the package, such as "go1.21.3". The symbols can depend on it,
+	// since it determines the standard library that the package is
+	// analyzed with.
+	BuildGoVersion string `json:",omitempty"`
+	// Skip is the reason that no symbols were derived,
+	// or NotSkipped if symbols were derived.
+	Skip SkipReason `json:"-"`
+}
+
+// ExportedResult is like ExportedSymbols, but distinguishes packages
+// that were intentionally skipped from packages for which no symbols
+// were found.
+//
+// It also reports the listed symbols of p that are not reachable from
+// any of its exported functions; see Result.UnreachableVulnSymbols.
+//
+// Unlike ExportedSymbols, ExportedResult does not return an error
+// if the loaded version of the module is not affected. Instead, it
+// returns a Result with Skip set to VersionNotAffected.
+func ExportedResult(m *report.Module, p *report.Package, opts *Options, errlog *log.Logger) (_ *Result, err error) {
+	defer derrors.Wrap(&err, "ExportedResult(%q, %q)", m.Module, p.Package)
+
+	if len(p.Symbols) == 0 {
+		return &Result{Skip: NoInputSymbols}, nil
+	}
+	res, err := cachedResult(m, p, opts, errlog)
+	switch {
+	case errors.Is(err, errNotAffected):
+		return &Result{Skip: VersionNotAffected}, nil
+	case err != nil:
 		return nil, err
 	}
+	return res, nil
+}
 
-	pkg, err := loadPackage(&packages.Config{}, p.Package)
+func exportedSymbols(m *report.Module, p *report.Package, opts *Options, errlog *log.Logger) (_ *Result, err error) {
+	pkg, err := opts.loader().LoadPackage(m, p, opts, errlog)
 	if err != nil {
 		return nil, err
 	}
@@ -86,48 +391,413 @@ func Exported(m *report.Module, p *report.Package, errlog *log.Logger) (_ []stri
 	if pkg.PkgPath != p.Package {
 		return nil, fmt.Errorf("first package had import path %s, wanted %s", pkg.PkgPath, p.Package)
 	}
-	if m.IsFirstParty() {
-		if pm := pkg.Module; pm != nil {
-			return nil, fmt.Errorf("got module %v, expected nil", pm)
-		}
-	} else {
-		if pm := pkg.Module; pm == nil || pm.Path != m.Module {
-			return nil, fmt.Errorf("got module %v, expected %s", pm, m.Module)
-		}
+	if err := checkPackageModule(pkg, m); err != nil {
+		return nil, err
 	}
 
 	if len(p.Symbols) == 0 {
-		return nil, nil // no symbols to derive from. skip.
+		return &Result{}, nil // no symbols to derive from. skip.
 	}
 
 	// Check to see that all symbols actually exist in the package.
 	// This should perhaps be a lint check, but lint doesn't
 	// load/typecheck packages at the moment, so do it here for now.
+	var missing []string
 	for _, sym := range p.Symbols {
-		if typ, method, ok := strings.Cut(sym, "."); ok {
-			n, ok := pkg.Types.Scope().Lookup(typ).(*types.TypeName)
-			if !ok {
-				errlog.Printf("package %s: %v: type not found\n", p.Package, typ)
+		if err := checkSymbol(pkg.Types, sym); err != nil {
+			if opts.strict {
+				missing = append(missing, err.Error())
 				continue
 			}
-			m, _, _ := types.LookupFieldOrMethod(n.Type(), true, pkg.Types, method)
-			if m == nil {
-				errlog.Printf("package %s: %v: method not found\n", p.Package, sym)
-			}
-		} else {
-			_, ok := pkg.Types.Scope().Lookup(typ).(*types.Func)
-			if !ok {
-				errlog.Printf("package %s: %v: func not found\n", p.Package, typ)
-			}
+			errlog.Printf("package %s: %v\n", p.Package, err)
 		}
 	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("package %s: %d listed symbols not found: %s", p.Package, len(missing), strings.Join(missing, "; "))
+	}
 
-	newsyms, err := exportedFunctions(pkg, m)
+	syms, unreachable, err := newSymbols(pkg, m, p.Symbols, opts, errlog)
 	if err != nil {
 		return nil, err
 	}
-	var newslice []string
-	for s := range newsyms {
+	return &Result{
+		Symbols:                syms,
+		UnreachableVulnSymbols: unreachable,
+		Positions:              symbolPositions(pkg, syms),
+	}, nil
+}
+
+// checkSymbol checks that sym, in the form used by report.Package.Symbols,
+// is a function or method of pkg.
+//
+// Variables and constants are reported separately from missing symbols,
+// since the functions that use them should be listed instead, as are
+// missing symbols named after the package itself.
+func checkSymbol(pkg *types.Package, sym string) error {
+	typ, method, isMethod := strings.Cut(sym, ".")
+	obj := pkg.Scope().Lookup(typ)
+	if isMethod {
+		n, ok := obj.(*types.TypeName)
+		if !ok {
+			return fmt.Errorf("%v: type not found", typ)
+		}
+		m, _, _ := types.LookupFieldOrMethod(n.Type(), true, pkg, method)
+		switch m.(type) {
+		case *types.Func:
+			return nil
+		case *types.Var:
+			return fmt.Errorf("symbol %q is a field, not a method", sym)
+		}
+		return fmt.Errorf("%v: method not found", sym)
+	}
+	switch obj.(type) {
+	case *types.Func:
+		return nil
+	case *types.Var:
+		return fmt.Errorf("symbol %q is a variable, not a function/method", sym)
+	case *types.Const:
+		return fmt.Errorf("symbol %q is a constant, not a function/method", sym)
+	case nil:
+		if sym == pkg.Name() || sym == path.Base(pkg.Path()) {
+			return fmt.Errorf("symbol %q looks like a package name, not a function", sym)
+		}
+	}
+	return fmt.Errorf("%v: func not found", sym)
+}
+
+// ExportedGlob is like Exported, but derives the vulnerable symbols
+// exported by every package of module m matching pattern.
+//
+// The pattern must be of the form "path/...", where path is within
+// module m. Packages in nested modules are ignored.
+// The result maps package paths to their derived symbols;
+// packages with no derived symbols are omitted.
+//
+// The Cache and Refresh fields of opts are not used.
+func ExportedGlob(m *report.Module, pattern string, opts *Options, errlog *log.Logger) (_ map[string][]string, err error) {
+	defer derrors.Wrap(&err, "ExportedGlob(%q, %q)", m.Module, pattern)
+
+	if opts == nil {
+		opts = &Options{}
+	}
+	if err := checkGlob(m, pattern); err != nil {
+		return nil, err
+	}
+	pkgs, err := loadGlob(m, pattern, opts, errlog)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string][]string)
+	for _, p := range m.Packages {
+		known[p.Package] = p.Symbols
+	}
+	result := make(map[string][]string)
+	for _, pkg := range pkgs {
+		if !m.IsFirstParty() && (pkg.Module == nil || pkg.Module.Path != m.Module) {
+			continue // nested module
+		}
+		syms, _, err := newSymbols(pkg, m, known[pkg.PkgPath], opts, errlog)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range syms {
+			result[pkg.PkgPath] = append(result[pkg.PkgPath], s.Name)
+		}
+	}
+	return result, nil
+}
+
+// loadGlob loads the packages of module m matching pattern, which must
+// have been checked by checkGlob, together. The packages of nested
+// modules that match the pattern are included.
+func loadGlob(m *report.Module, pattern string, opts *Options, errlog *log.Logger) ([]*packages.Package, error) {
+	if err := opts.checkSource(m); err != nil {
+		return nil, err
+	}
+
+	dir, cleanup, err := makeTempDir()
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	if err := initModule(dir, m, opts, errlog); err != nil {
+		return nil, err
+	}
+	if !m.IsFirstParty() && opts.LocalDir == "" {
+		// Download the module so that the pattern can be expanded
+		// against its contents.
+		if err := run(errlog, dir, opts.Env, "go", "mod", "download", m.Module+"@v"+m.VulnerableAt); err != nil {
+			return nil, err
+		}
+	}
+	out, err := command(dir, opts.Env, "go", "list", "-e", "-f", "{{.ImportPath}}", pattern).Output()
+	if err != nil {
+		var ee *exec.ExitError
+		if errors.As(err, &ee) {
+			errlog.Println(string(ee.Stderr))
+		}
+		return nil, err
+	}
+	paths := strings.Fields(string(out))
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no packages match %s", pattern)
+	}
+	if err := requirePackages(dir, m, paths, opts.Env, errlog); err != nil {
+		return nil, err
+	}
+
+	return loadPackages(opts.packagesConfig(dir), paths...)
+}
+
+// checkPackageModule checks that pkg was loaded from module m.
+func checkPackageModule(pkg *packages.Package, m *report.Module) error {
+	pm := pkg.Module
+	if m.IsFirstParty() {
+		if pm != nil {
+			return fmt.Errorf("got module %v, expected nil", pm)
+		}
+		return nil
+	}
+	switch {
+	case pm == nil:
+		return fmt.Errorf("got module %v, expected %s", pm, m.Module)
+	case pm.Path != m.Module && inModule(m.Module, pm.Path):
+		// Requiring m does not provide packages in nested modules,
+		// so pkg was resolved from some other version of the nested module.
+		return fmt.Errorf("package %s is in nested module %s, not %s; use module %s (at the vulnerable version) in the report", pkg.PkgPath, pm.Path, m.Module, pm.Path)
+	case pm.Path != m.Module:
+		return fmt.Errorf("got module %v, expected %s", pm, m.Module)
+	}
+	return nil
+}
+
+// checkGlob checks that pattern is of the form "path/..."
+// with path in module m.
+func checkGlob(m *report.Module, pattern string) error {
+	if !strings.HasSuffix(pattern, "/...") {
+		return fmt.Errorf("pattern %q must end in /...", pattern)
+	}
+	path := strings.TrimSuffix(pattern, "/...")
+	var ok bool
+	switch {
+	case stdlib.IsStdModule(m.Module):
+		ok = stdlib.Contains(path) && !inModule(stdlib.ToolchainModulePath, path)
+	default:
+		ok = inModule(m.Module, path)
+	}
+	if !ok {
+		return fmt.Errorf("pattern %q is not within module %s", pattern, m.Module)
+	}
+	return nil
+}
+
+// inModule reports whether the package path is within
+// the module path modPath (ignoring nested modules).
+func inModule(modPath, path string) bool {
+	return path == modPath || strings.HasPrefix(path, modPath+"/")
+}
+
+// run runs the given command in dir with the additional environment
+// variables in env, logging its output to errlog if it fails.
+func run(errlog *log.Logger, dir string, env []string, name string, arg ...string) error {
+	out, err := command(dir, env, name, arg...).CombinedOutput()
+	if err != nil {
+		errlog.Println(string(out))
+	}
+	return err
+}
+
+// goVersions caches the results of buildGoVersion, keyed by environment.
+var goVersions sync.Map
+
+// buildGoVersion returns the version of the go command that is run
+// with the additional environment variables in env, such as "go1.21.3".
+// This can differ from the version this program was built with, and
+// from one environment to another (for example, with GOTOOLCHAIN).
+func buildGoVersion(env []string) (_ string, err error) {
+	defer derrors.Wrap(&err, "buildGoVersion(%q)", env)
+
+	key := strings.Join(env, "\x00")
+	if v, ok := goVersions.Load(key); ok {
+		return v.(string), nil
+	}
+	out, err := command("", env, "go", "env", "GOVERSION").Output()
+	if err != nil {
+		return "", err
+	}
+	v := strings.TrimSpace(string(out))
+	goVersions.Store(key, v)
+	return v, nil
+}
+
+// command returns a command that runs name with the given arguments
+// in dir (or the current directory, if dir is ""), and the additional
+// environment variables in env.
+func command(dir string, env []string, name string, arg ...string) *exec.Cmd {
+	cmd := exec.Command(name, arg...)
+	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	return cmd
+}
+
+// initModule creates a go.mod file in dir
+// that requires m at its vulnerable_at version (or the revision or
+// local directory given in opts), running the go commands with the
+// additional environment variables in opts.Env.
+func initModule(dir string, m *report.Module, opts *Options, errlog *log.Logger) error {
+	env := opts.Env
+	// This procedure was developed through trial and error finding a way
+	// to load symbols for GO-2023-1549, which has a dependency tree that
+	// includes go.mod files that reference v0.0.0 versions which do not exist.
+	//
+	// Create an empty go.mod.
+	if err := run(errlog, dir, env, "go", "mod", "init", "go.dev/_"); err != nil {
+		return err
+	}
+	if m.IsFirstParty() {
+		return nil
+	}
+	// Require the module we're interested in at the vulnerable_at version,
+	// or at the revision or local directory given in opts.
+	var err error
+	switch {
+	case opts.LocalDir != "":
+		// The required version is arbitrary, as it is replaced.
+		err = run(errlog, dir, env, "go", "mod", "edit",
+			"-require", m.Module+"@"+localVersion,
+			"-replace", m.Module+"="+opts.LocalDir)
+	case version.IsCommitHash(opts.Revision):
+		// Only the go command can resolve a commit to a pseudo-version.
+		err = run(errlog, dir, env, "go", "get", m.Module+"@"+opts.Revision)
+	case opts.Revision != "":
+		err = run(errlog, dir, env, "go", "mod", "edit", "-require", m.Module+"@v"+opts.Revision)
+	default:
+		err = run(errlog, dir, env, "go", "mod", "edit", "-require", m.Module+"@v"+m.VulnerableAt)
+	}
+	if err != nil {
+		return err
+	}
+	for _, req := range m.VulnerableAtRequires {
+		if err := run(errlog, dir, env, "go", "mod", "edit", "-require", req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// requirePackages creates a package in dir that imports the given
+// packages of m, and runs go mod tidy with the additional environment
+// variables in env.
+func requirePackages(dir string, m *report.Module, pkgPaths []string, env []string, errlog *log.Logger) error {
+	if !m.IsFirstParty() {
+		if err := os.WriteFile(filepath.Join(dir, "p.go"), importStub(m, pkgPaths), 0666); err != nil {
+			return err
+		}
+	}
+	// Run go mod tidy.
+	return run(errlog, dir, env, "go", "mod", "tidy")
+}
+
+// importStub returns the contents of a Go file for a package that
+// imports the given packages of m, and the packages in
+// m.VulnerableAtRequires.
+func importStub(m *report.Module, pkgPaths []string) []byte {
+	var content bytes.Buffer
+	fmt.Fprintf(&content, "package p\n")
+	for _, p := range pkgPaths {
+		fmt.Fprintf(&content, "import _ %q\n", p)
+	}
+	for _, req := range m.VulnerableAtRequires {
+		pkg, _, _ := strings.Cut(req, "@")
+		fmt.Fprintf(&content, "import _ %q\n", pkg)
+	}
+	return content.Bytes()
+}
+
+// An ExtractionPlan describes the module that Exported builds
+// in order to load a package.
+type ExtractionPlan struct {
+	// GoMod is the contents of the go.mod file, before
+	// running go mod tidy.
+	GoMod string
+	// ImportStub is the contents of the Go file that imports
+	// the package, or "" if none is needed (for first-party modules).
+	ImportStub string
+	// Requires are the module requirements in GoMod,
+	// in the form "path@version".
+	Requires []string
+}
+
+// ExportedPlan returns the module that Exported would build to
+// load package p of module m with the given options, without running
+// go mod tidy or loading any packages. It is intended for debugging
+// extraction failures.
+func ExportedPlan(m *report.Module, p *report.Package, opts *Options, errlog *log.Logger) (_ *ExtractionPlan, err error) {
+	defer derrors.Wrap(&err, "ExportedPlan(%q, %q)", m.Module, p.Package)
+
+	if opts == nil {
+		opts = &Options{}
+	}
+	if err := opts.checkSource(m); err != nil {
+		return nil, err
+	}
+	dir, cleanup, err := makeTempDir()
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	if err := initModule(dir, m, opts, errlog); err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+	f, err := modfile.Parse("go.mod", b, nil)
+	if err != nil {
+		return nil, err
+	}
+	plan := &ExtractionPlan{GoMod: string(b)}
+	for _, r := range f.Require {
+		plan.Requires = append(plan.Requires, r.Mod.String())
+	}
+	if !m.IsFirstParty() {
+		plan.ImportStub = string(importStub(m, []string{p.Package}))
+	}
+	return plan, nil
+}
+
+// newSymbols returns the vulnerable symbols exported by pkg
+// that are not already in known, sorted by name, and the symbols
+// in known that can't be reached from any exported function or
+// method of pkg, also sorted by name.
+func newSymbols(pkg *packages.Package, m *report.Module, known []string, opts *Options, errlog *log.Logger) (_ []*Symbol, unreachable []string, err error) {
+	syms, reached, err := exportedFunctions(pkg, m, opts.DebugSSA, errlog)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, s := range known {
+		if !reached[s] {
+			unreachable = append(unreachable, s)
+		}
+	}
+	sort.Strings(unreachable)
+	if opts.AssumeDynamicCalls {
+		if reason := dynamicCallReason(pkg); reason != "" {
+			errlog.Printf("package %s: %s; considering all exported functions vulnerable\n", pkg.PkgPath, reason)
+			for name, sym := range allExportedFunctions(pkg, reason) {
+				if _, ok := syms[name]; !ok {
+					syms[name] = sym
+				}
+			}
+		}
+	}
+	var newslice []*Symbol
+	for s, sym := range syms {
 		if s == "init" {
 			// Exclude init funcs from consideration.
 			//
@@ -137,33 +807,60 @@ func Exported(m *report.Module, p *report.Package, errlog *log.Logger) (_ []stri
 			// untrusted input).
 			continue
 		}
-		if !slices.Contains(p.Symbols, s) {
-			newslice = append(newslice, s)
+		if !slices.Contains(known, s) {
+			newslice = append(newslice, sym)
 		}
 	}
-	sort.Strings(newslice)
-	return newslice, nil
+	sort.Slice(newslice, func(i, j int) bool {
+		return newslice[i].Name < newslice[j].Name
+	})
+	return newslice, unreachable, nil
 }
 
-// exportedFunctions returns a set of vulnerable functions exported
-// by a packages from the module.
-func exportedFunctions(pkg *packages.Package, m *report.Module) (_ map[string]bool, err error) {
+var errNotAffected = errors.New("not affected by this vuln")
+
+// localVersion is the version at which a module replaced by a local
+// directory is required.
+const localVersion = "v0.0.0-00010101000000-000000000000"
+
+// isLocal reports whether m is replaced by a local directory.
+func isLocal(m *packages.Module) bool {
+	return m.Replace != nil && m.Replace.Version == ""
+}
+
+// exportedFunctions returns the vulnerable functions exported
+// by a packages from the module, keyed by symbol name, and the set
+// of the vulnerable symbols of the package that they reach, in the
+// format of report.Package.Symbols.
+//
+// If debug is non-nil, the SSA of the analysis is written to it
+// (see Options.DebugSSA). Functions that reach only the derived
+// symbols of m, and none of those listed, are logged to errlog and
+// left out.
+func exportedFunctions(pkg *packages.Package, m *report.Module, debug io.Writer, errlog *log.Logger) (_ map[string]*Symbol, reached map[string]bool, err error) {
 	defer derrors.Wrap(&err, "exportedFunctions(%q)", pkg.PkgPath)
 
-	if pkg.Module != nil {
+	// A module replaced by a directory (see Options.LocalDir) has no
+	// meaningful version.
+	if pkg.Module != nil && !isLocal(pkg.Module) {
 		v := version.TrimPrefix(pkg.Module.Version)
 		affected, err := osvutils.AffectsSemver(report.AffectedRanges(m.Versions), v)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		if !affected {
-			return nil, fmt.Errorf("version %s of module %s is not affected by this vuln", v, pkg.Module.Path)
+			return nil, nil, fmt.Errorf("version %s of module %s is %w", v, pkg.Module.Path, errNotAffected)
 		}
 	}
 
-	entries, err := vulnEntries([]*packages.Package{pkg}, m)
+	entries, vulns, unlisted, err := vulnEntries([]*packages.Package{pkg}, m, debug)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	for _, e := range unlisted {
+		if pkgPath(e) == pkg.PkgPath {
+			errlog.Printf("package %s: %s reaches only derived symbols, not a listed one; ignoring it\n", pkg.PkgPath, ssaSymbolName(e))
+		}
 	}
 	// Return the name of all entry points.
 	// Note that "main" and "init" are both possible entries.
@@ -172,13 +869,118 @@ func exportedFunctions(pkg *packages.Package, m *report.Module) (_ map[string]bo
 	// some global state is altered, and so every exported function
 	// is vulnerable. For now, we leave it to consumers to use this
 	// information as they wish.
-	names := map[string]bool{}
+	syms := map[string]*Symbol{}
 	for _, e := range entries {
 		if pkgPath(e) == pkg.PkgPath {
-			names[ssaSymbolName(e)] = true
+			name := ssaSymbolName(e)
+			syms[name] = &Symbol{
+				Name:       name,
+				Deprecated: isDeprecated(e),
+			}
 		}
 	}
-	return names, nil
+	reached = make(map[string]bool)
+	for _, f := range vulns {
+		if pkgPath(f) == pkg.PkgPath {
+			reached[dbFuncName(f)] = true
+		}
+	}
+	return syms, reached, nil
+}
+
+// symbolPositions returns the positions of the declarations of syms
+// in pkg, keyed by symbol name, with filenames relative to the root of
+// pkg's module. Symbols without a declaration of their own, such as
+// methods promoted from embedded fields, are omitted.
+func symbolPositions(pkg *packages.Package, syms []*Symbol) map[string]token.Position {
+	root := moduleRoot(pkg)
+	var positions map[string]token.Position
+	for _, s := range syms {
+		obj := lookupFunc(pkg.Types, s.Name)
+		if obj == nil || !obj.Pos().IsValid() {
+			continue
+		}
+		if positions == nil {
+			positions = make(map[string]token.Position)
+		}
+		positions[s.Name] = relativePosition(pkg.Fset.Position(obj.Pos()), root)
+	}
+	return positions
+}
+
+// lookupFunc returns the function or method sym declared in pkg,
+// or nil if there is none. Methods promoted from embedded fields
+// are not declared by their receiver type, so nil is returned
+// for them.
+func lookupFunc(pkg *types.Package, sym string) *types.Func {
+	typ, method, isMethod := strings.Cut(sym, ".")
+	obj := pkg.Scope().Lookup(typ)
+	if !isMethod {
+		f, _ := obj.(*types.Func)
+		return f
+	}
+	n, ok := obj.(*types.TypeName)
+	if !ok {
+		return nil
+	}
+	m, index, _ := types.LookupFieldOrMethod(n.Type(), true, pkg, method)
+	if len(index) != 1 {
+		return nil
+	}
+	f, _ := m.(*types.Func)
+	return f
+}
+
+// moduleRoot returns the root directory of the module containing pkg,
+// or "" if it can't be determined. For packages of the standard
+// library, which have no module, it is GOROOT/src.
+func moduleRoot(pkg *packages.Package) string {
+	if pkg.Module != nil && pkg.Module.Dir != "" {
+		return pkg.Module.Dir
+	}
+	if len(pkg.GoFiles) == 0 {
+		return ""
+	}
+	// The package's directory ends with the path of the package
+	// relative to the module, which for a package without a module
+	// is the package path itself.
+	rel := pkg.PkgPath
+	if pkg.Module != nil {
+		rel = strings.TrimPrefix(strings.TrimPrefix(pkg.PkgPath, pkg.Module.Path), "/")
+	}
+	dir := filepath.Dir(pkg.GoFiles[0])
+	root := strings.TrimSuffix(dir, filepath.FromSlash(rel))
+	if root == dir && rel != "" {
+		return ""
+	}
+	return filepath.Clean(root)
+}
+
+// relativePosition returns p with a slash-separated filename relative
+// to root, or p unchanged if its file is not within root.
+func relativePosition(p token.Position, root string) token.Position {
+	if root == "" {
+		return p
+	}
+	if rel, err := filepath.Rel(root, p.Filename); err == nil && !strings.HasPrefix(rel, "..") {
+		p.Filename = filepath.ToSlash(rel)
+	}
+	return p
+}
+
+// isDeprecated reports whether the doc comment of fn's declaration
+// has a paragraph beginning with "Deprecated: ".
+func isDeprecated(fn *ssa.Function) bool {
+	decl, ok := fn.Syntax().(*ast.FuncDecl)
+	if !ok || decl.Doc == nil {
+		return false
+	}
+	for _, para := range strings.Split(decl.Doc.Text(), "\n\n") {
+		if strings.HasPrefix(para, "Deprecated: ") {
+			return true
+		}
+	}
+	return false
 }
 
 func ssaSymbolName(fn *ssa.Function) string {
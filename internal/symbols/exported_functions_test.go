@@ -5,10 +5,25 @@
 package symbols
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"log"
+	"os"
 	"path"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/go/packages/packagestest"
 	"golang.org/x/vulndb/internal/report"
 )
@@ -32,6 +47,11 @@ func TestExportedFunctions(t *testing.T) {
 					func Trans() { Exp() }
 					func Fine() { ok() }
 
+					// Old calls vuln.
+					//
+					// Deprecated: use Exp instead.
+					func Old() { vuln() }
+
 					type D struct {}
 					func (d D) Dep() {
 						vl := v.V{}
@@ -71,12 +91,991 @@ func TestExportedFunctions(t *testing.T) {
 	pkg.Module.Dir = ""
 	pkg.Module.Version = "v1.0.0"
 
-	got, err := exportedFunctions(pkg, m)
+	got, _, err := exportedFunctions(pkg, m, nil, log.New(io.Discard, "", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]*Symbol{
+		"Exp":   {Name: "Exp"},
+		"Trans": {Name: "Trans"},
+		"D.Dep": {Name: "D.Dep"},
+		"Old":   {Name: "Old", Deprecated: true},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestExportedFunctionsSameName(t *testing.T) {
+	e := packagestest.Export(t, packagestest.Modules, []packagestest.Module{
+		{
+			Name: "example.com/m",
+			Files: map[string]interface{}{
+				"p/p.go": `
+					package p
+
+					import "example.com/m/q"
+
+					func Vuln() {}
+					func Exp() { Vuln() }
+
+					// Other calls a function with the same name
+					// as the vulnerable symbol, in another package.
+					func Other() { q.Vuln() }
+					func Wrap() { Other() }
+				`,
+				"q/q.go": `
+					package q
+
+					func Vuln() {}
+				`,
+			},
+		},
+	})
+	defer e.Cleanup()
+
+	m := &report.Module{
+		Module: "example.com/m",
+		Packages: []*report.Package{
+			{
+				Package: "example.com/m/p",
+				Symbols: []string{"Vuln"},
+				// Mistakenly derived from the call to q.Vuln.
+				DerivedSymbols: []string{"Exp", "Other"},
+			},
+		},
+	}
+	pkg, err := loadPackage(e.Config, path.Join(e.Temp(), "m/p"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg.Module.Dir = ""
+	pkg.Module.Version = "v1.0.0"
+
+	var buf bytes.Buffer
+	got, _, err := exportedFunctions(pkg, m, nil, log.New(&buf, "", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]*Symbol{
+		"Exp":  {Name: "Exp"},
+		"Vuln": {Name: "Vuln"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	for _, name := range []string{"Other", "Wrap"} {
+		if msg := name + " reaches only derived symbols"; !strings.Contains(buf.String(), msg) {
+			t.Errorf("log = %q, want it to contain %q", buf.String(), msg)
+		}
+	}
+}
+
+func TestExportedFunctionsNotAffected(t *testing.T) {
+	e := packagestest.Export(t, packagestest.Modules, []packagestest.Module{
+		{
+			Name: "example.com/m",
+			Files: map[string]interface{}{
+				"p/a.go": `
+					package p
+					func Vuln() {}
+				`,
+			},
+		},
+	})
+	defer e.Cleanup()
+
+	m := &report.Module{
+		Module:   "example.com/m",
+		Versions: []report.VersionRange{{Fixed: "1.0.0"}},
+		Packages: []*report.Package{
+			{
+				Package: "example.com/m/p",
+				Symbols: []string{"Vuln"},
+			},
+		},
+	}
+	pkg, err := loadPackage(e.Config, path.Join(e.Temp(), "m/p"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg.Module.Version = "v1.0.0"
+
+	if _, _, err := exportedFunctions(pkg, m, nil, log.New(io.Discard, "", 0)); !errors.Is(err, errNotAffected) {
+		t.Errorf("exportedFunctions() error = %v, want %v", err, errNotAffected)
+	}
+}
+
+func TestNewSymbolsAssumeDynamicCalls(t *testing.T) {
+	e := packagestest.Export(t, packagestest.Modules, []packagestest.Module{
+		{
+			Name: "example.com/m",
+			Files: map[string]interface{}{
+				"p/p.go": `
+					package p
+
+					import _ "unsafe"
+
+					func vuln() {}
+					func Exp() { vuln() }
+
+					// now may be called from anywhere.
+					//go:linkname now
+					func now() { vuln() }
+
+					type T struct{}
+					func (T) M()       {}
+					func (*T) PM()     {}
+					func (T) private() {}
+
+					type t struct{}
+					func (t) M() {}
+				`,
+				"q/q.go": `
+					package q
+
+					func vuln() {}
+					func Exp() { vuln() }
+					func Other() {}
+				`,
+			},
+		},
+	})
+	defer e.Cleanup()
+
+	m := &report.Module{
+		Module: "example.com/m",
+		Packages: []*report.Package{
+			{Package: "example.com/m/p", Symbols: []string{"vuln"}},
+			{Package: "example.com/m/q", Symbols: []string{"vuln"}},
+		},
+	}
+	const reason = "p.go:10: uses //go:linkname"
+	for _, test := range []struct {
+		pkg     string
+		opts    *Options
+		want    []*Symbol
+		wantLog string
+	}{
+		{
+			pkg:  "p",
+			opts: &Options{},
+			want: []*Symbol{{Name: "Exp"}},
+		},
+		{
+			pkg:  "p",
+			opts: &Options{AssumeDynamicCalls: true},
+			want: []*Symbol{
+				{Name: "Exp"},
+				{Name: "T.M", Reason: reason},
+				{Name: "T.PM", Reason: reason},
+				{Name: "t.M", Reason: reason},
+			},
+			wantLog: "package example.com/m/p: " + reason + "; considering all exported functions vulnerable\n",
+		},
+		{
+			// No dynamic calls.
+			pkg:  "q",
+			opts: &Options{AssumeDynamicCalls: true},
+			want: []*Symbol{{Name: "Exp"}},
+		},
+	} {
+		t.Run(fmt.Sprintf("%s/%t", test.pkg, test.opts.AssumeDynamicCalls), func(t *testing.T) {
+			pkg, err := loadPackage(e.Config, path.Join(e.Temp(), "m", test.pkg))
+			if err != nil {
+				t.Fatal(err)
+			}
+			pkg.Module.Dir = ""
+			pkg.Module.Version = "v1.0.0"
+
+			var buf bytes.Buffer
+			got, _, err := newSymbols(pkg, m, []string{"vuln"}, test.opts, log.New(&buf, "", 0))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+			if got := buf.String(); got != test.wantLog {
+				t.Errorf("log = %q, want %q", got, test.wantLog)
+			}
+		})
+	}
+}
+
+// fakeLoader is a PackageLoader that returns a fixed package.
+type fakeLoader struct {
+	pkg    *packages.Package
+	loaded []string // the packages requested
+}
+
+func (l *fakeLoader) LoadPackage(_ *report.Module, p *report.Package, _ *Options, _ *log.Logger) (*packages.Package, error) {
+	l.loaded = append(l.loaded, p.Package)
+	return l.pkg, nil
+}
+
+func TestExportedSymbolsLoader(t *testing.T) {
+	e := packagestest.Export(t, packagestest.Modules, []packagestest.Module{
+		{
+			Name: "example.com/m",
+			Files: map[string]interface{}{
+				"p/p.go": `
+					package p
+
+					func vuln() {}
+					func Exp() { vuln() }
+					func Other() {}
+				`,
+			},
+		},
+	})
+	defer e.Cleanup()
+
+	pkg, err := loadPackage(e.Config, path.Join(e.Temp(), "m", "p"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg.Module.Dir = ""
+	pkg.Module.Version = "v1.0.0"
+
+	p := &report.Package{Package: "example.com/m/p", Symbols: []string{"vuln"}}
+	m := &report.Module{Module: "example.com/m", VulnerableAt: "1.0.0", Packages: []*report.Package{p}}
+	loader := &fakeLoader{pkg: pkg}
+	// The cache is not used with a custom loader.
+	opts := &Options{Loader: loader, Cache: &Cache{dir: t.TempDir()}}
+	got, err := ExportedSymbols(m, p, opts, log.New(io.Discard, "", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]*Symbol{{Name: "Exp"}}, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"example.com/m/p"}, loader.loaded); diff != "" {
+		t.Errorf("loaded packages mismatch (-want, +got):\n%s", diff)
+	}
+
+	// The loaded package must match the requested one.
+	other := &report.Package{Package: "example.com/m/q", Symbols: []string{"vuln"}}
+	if _, err := ExportedSymbols(m, other, opts, log.New(io.Discard, "", 0)); err == nil {
+		t.Error("got nil error for mismatched package, want error")
+	}
+}
+
+func TestExportedSymbolsStrict(t *testing.T) {
+	e := packagestest.Export(t, packagestest.Modules, []packagestest.Module{
+		{
+			Name: "example.com/m",
+			Files: map[string]interface{}{
+				"p/p.go": `
+					package p
+
+					var V int
+
+					func vuln() {}
+					func Exp() { vuln() }
+				`,
+			},
+		},
+	})
+	defer e.Cleanup()
+
+	pkg, err := loadPackage(e.Config, path.Join(e.Temp(), "m", "p"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg.Module.Dir = ""
+	pkg.Module.Version = "v1.0.0"
+
+	for _, test := range []struct {
+		name    string
+		symbols []string
+		strict  bool
+		want    []*Symbol
+		wantLog string
+		wantErr string
+	}{
+		{
+			name:    "lenient",
+			symbols: []string{"vuln", "missing", "V"},
+			want:    []*Symbol{{Name: "Exp"}},
+			wantLog: "package example.com/m/p: missing: func not found\n" +
+				"package example.com/m/p: symbol \"V\" is a variable, not a function/method\n",
+		},
+		{
+			name:    "strict",
+			symbols: []string{"vuln", "missing", "V"},
+			strict:  true,
+			wantErr: `package example.com/m/p: 2 listed symbols not found: missing: func not found; symbol "V" is a variable, not a function/method`,
+		},
+		{
+			name:    "strict, all found",
+			symbols: []string{"vuln"},
+			strict:  true,
+			want:    []*Symbol{{Name: "Exp"}},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			p := &report.Package{Package: "example.com/m/p", Symbols: test.symbols}
+			m := &report.Module{Module: "example.com/m", VulnerableAt: "1.0.0", Packages: []*report.Package{p}}
+			var buf bytes.Buffer
+			opts := &Options{Loader: &fakeLoader{pkg: pkg}, strict: test.strict}
+			got, err := ExportedSymbols(m, p, opts, log.New(&buf, "", 0))
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("got error %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("mismatch (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(test.wantLog, buf.String()); diff != "" {
+				t.Errorf("log mismatch (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSymbolPositions(t *testing.T) {
+	e := packagestest.Export(t, packagestest.Modules, []packagestest.Module{
+		{
+			Name: "example.com/m",
+			Files: map[string]interface{}{
+				"p/p.go": `package p
+
+type T struct{ U }
+
+func (T) M() {}
+
+type U struct{}
+
+func (*U) N() {}
+
+func F() {}
+`,
+			},
+		},
+	})
+	defer e.Cleanup()
+
+	pkg, err := loadPackage(e.Config, path.Join(e.Temp(), "m", "p"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// T.N is promoted from U, so it has no declaration of its own.
+	syms := []*Symbol{{Name: "F"}, {Name: "T.M"}, {Name: "T.N"}, {Name: "U.N"}}
+	got := symbolPositions(pkg, syms)
+	want := map[string]token.Position{
+		"T.M": {Filename: "p/p.go", Line: 5, Column: 10},
+		"U.N": {Filename: "p/p.go", Line: 9, Column: 11},
+		"F":   {Filename: "p/p.go", Line: 11, Column: 6},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(token.Position{}, "Offset")); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestExportedResultNoInputSymbols(t *testing.T) {
+	p := &report.Package{Package: "example.com/m/p"}
+	m := &report.Module{Module: "example.com/m", VulnerableAt: "1.0.0", Packages: []*report.Package{p}}
+	loader := &fakeLoader{}
+	got, err := ExportedResult(m, p, &Options{Loader: loader}, log.New(io.Discard, "", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (&Result{Skip: NoInputSymbols}); !cmp.Equal(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if len(loader.loaded) != 0 {
+		t.Errorf("loaded %v, want no packages loaded", loader.loaded)
+	}
+}
+
+func TestExportedResultUnreachable(t *testing.T) {
+	e := packagestest.Export(t, packagestest.Modules, []packagestest.Module{
+		{
+			Name: "example.com/m",
+			Files: map[string]interface{}{
+				"p/p.go": `
+					package p
+
+					func vuln() {}
+					func Exp() { vuln() }
+
+					// Listed, but only called by other unexported functions.
+					func dead() {}
+					func helper() { dead() }
+
+					// Listed and exported, so reachable by itself.
+					func Listed() {}
+				`,
+			},
+		},
+	})
+	defer e.Cleanup()
+
+	pkg, err := loadPackage(e.Config, path.Join(e.Temp(), "m", "p"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg.Module.Dir = ""
+	pkg.Module.Version = "v1.0.0"
+
+	p := &report.Package{Package: "example.com/m/p", Symbols: []string{"vuln", "dead", "Listed"}}
+	m := &report.Module{Module: "example.com/m", VulnerableAt: "1.0.0", Packages: []*report.Package{p}}
+	got, err := ExportedResult(m, p, &Options{Loader: &fakeLoader{pkg: pkg}}, log.New(io.Discard, "", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	goVersion, err := buildGoVersion(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Result{
+		Symbols:                []*Symbol{{Name: "Exp"}},
+		UnreachableVulnSymbols: []string{"dead"},
+		Positions:              map[string]token.Position{"Exp": {Filename: "p/p.go", Line: 5, Column: 11}},
+		BuildGoVersion:         goVersion,
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(token.Position{}, "Offset")); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestExportedSymbolsDebugSSA(t *testing.T) {
+	e := packagestest.Export(t, packagestest.Modules, []packagestest.Module{
+		{
+			Name: "example.com/m",
+			Files: map[string]interface{}{
+				"p/p.go": `
+					package p
+
+					func vuln() {}
+					func Exp() { vuln() }
+					func Other() {}
+				`,
+			},
+		},
+	})
+	defer e.Cleanup()
+
+	pkg, err := loadPackage(e.Config, path.Join(e.Temp(), "m", "p"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg.Module.Dir = ""
+	pkg.Module.Version = "v1.0.0"
+
+	p := &report.Package{Package: "example.com/m/p", Symbols: []string{"vuln"}}
+	m := &report.Module{Module: "example.com/m", VulnerableAt: "1.0.0", Packages: []*report.Package{p}}
+	var buf bytes.Buffer
+	opts := &Options{Loader: &fakeLoader{pkg: pkg}, DebugSSA: &buf}
+	if _, err := ExportedSymbols(m, p, opts, log.New(io.Discard, "", 0)); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"package example.com/m/p:",       // the package
+		"# Name: example.com/m/p.Exp\n",  // on the vulnerable path
+		"# Name: example.com/m/p.vuln\n", // the vulnerable symbol
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("SSA output does not contain %q:\n%s", want, got)
+		}
+	}
+	// Functions off the vulnerable path are not written in full.
+	if strings.Contains(got, "# Name: example.com/m/p.Other\n") {
+		t.Errorf("SSA output contains function Other:\n%s", got)
+	}
+}
+
+func TestDynamicCallReason(t *testing.T) {
+	e := packagestest.Export(t, packagestest.Modules, []packagestest.Module{
+		{
+			Name: "example.com/m",
+			Files: map[string]interface{}{
+				"call/call.go": `
+					package call
+
+					import "reflect"
+
+					func Call(f interface{}) { reflect.ValueOf(f).Call(nil) }
+				`,
+				"callslice/callslice.go": `
+					package callslice
+
+					import "reflect"
+
+					func Call(f reflect.Value) { f.CallSlice(nil) }
+				`,
+				"linkname/linkname.go": `
+					package linkname
+
+					import _ "unsafe"
+
+					//go:linkname now time.now
+					func now() (int64, int32, int64)
+				`,
+				"linkname/linkname.s": "",
+				"none/none.go": `
+					package none
+
+					import "reflect"
+
+					// Call is the same as reflect.Value.Call.
+					// go:linkname is not a directive.
+					func Call(f reflect.Value) { f.Interface() }
+				`,
+			},
+		},
+	})
+	defer e.Cleanup()
+
+	for _, test := range []struct {
+		pkg  string
+		want string
+	}{
+		{"call", "call.go:6: calls (reflect.Value).Call"},
+		{"callslice", "callslice.go:6: calls (reflect.Value).CallSlice"},
+		{"linkname", "linkname.go:6: uses //go:linkname"},
+		{"none", ""},
+	} {
+		t.Run(test.pkg, func(t *testing.T) {
+			pkg, err := loadPackage(e.Config, path.Join(e.Temp(), "m", test.pkg))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := dynamicCallReason(pkg); got != test.want {
+				t.Errorf("dynamicCallReason() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCheckPackageModuleNested(t *testing.T) {
+	e := packagestest.Export(t, packagestest.Modules, []packagestest.Module{
+		{
+			Name: "example.com/foo",
+			Files: map[string]interface{}{
+				"foo.go": "package foo\n",
+			},
+		},
+		{
+			Name: "example.com/foo/sub",
+			Files: map[string]interface{}{
+				"p/p.go": "package p\n",
+			},
+		},
+	})
+	defer e.Cleanup()
+
+	pkg, err := loadPackage(e.Config, "example.com/foo/sub/p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		m       *report.Module
+		wantErr string
+	}{
+		{
+			m: &report.Module{Module: "example.com/foo/sub"},
+		},
+		{
+			m:       &report.Module{Module: "example.com/foo"},
+			wantErr: "package example.com/foo/sub/p is in nested module example.com/foo/sub, not example.com/foo; use module example.com/foo/sub",
+		},
+		{
+			m:       &report.Module{Module: "example.com/bar"},
+			wantErr: "expected example.com/bar",
+		},
+	} {
+		err := checkPackageModule(pkg, test.m)
+		switch {
+		case test.wantErr == "" && err != nil:
+			t.Errorf("%s: got error %v, want nil", test.m.Module, err)
+		case test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)):
+			t.Errorf("%s: got error %v, want error containing %q", test.m.Module, err, test.wantErr)
+		}
+	}
+}
+
+func TestCheckSymbol(t *testing.T) {
+	const src = `package p
+
+var DefaultClient = &Client{}
+
+const MaxSize = 10
+
+type Client struct{ Timeout int }
+
+func (*Client) Do() {}
+
+func Get() {}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := new(types.Config).Check("example.com/p", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		sym     string
+		wantErr string
+	}{
+		{sym: "Get"},
+		{sym: "Client.Do"},
+		{sym: "DefaultClient", wantErr: `symbol "DefaultClient" is a variable, not a function/method`},
+		{sym: "MaxSize", wantErr: `symbol "MaxSize" is a constant, not a function/method`},
+		{sym: "Client.Timeout", wantErr: `symbol "Client.Timeout" is a field, not a method`},
+		{sym: "Put", wantErr: "Put: func not found"},
+		{sym: "Client", wantErr: "Client: func not found"},
+		{sym: "Client.Close", wantErr: "Client.Close: method not found"},
+		{sym: "Server.Do", wantErr: "Server: type not found"},
+		{sym: "p", wantErr: `symbol "p" looks like a package name, not a function`},
+	} {
+		err := checkSymbol(pkg, test.sym)
+		switch {
+		case test.wantErr == "" && err != nil:
+			t.Errorf("%s: got error %v, want nil", test.sym, err)
+		case test.wantErr != "" && (err == nil || err.Error() != test.wantErr):
+			t.Errorf("%s: got error %v, want %q", test.sym, err, test.wantErr)
+		}
+	}
+}
+
+func TestLoadPackagesModMod(t *testing.T) {
+	// Module example.com/a imports a package of example.com/b, which is
+	// replaced by a local directory but is missing from the requirements.
+	// Loading it therefore only works with -mod=mod.
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"go.mod":   "module example.com/a\n\ngo 1.18\n\nreplace example.com/b => ./b\n",
+		"a.go":     "package a\n\nimport _ \"example.com/b\"\n",
+		"b/go.mod": "module example.com/b\n\ngo 1.18\n",
+		"b/b.go":   "package b\n",
+	} {
+		filename := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Clear GOFLAGS so that the test does not depend on the
+	// environment's default -mod setting.
+	env := []string{"GOFLAGS="}
+	cfg := (&Options{Env: env}).packagesConfig(dir)
+	if _, err := loadPackages(cfg, "example.com/a"); err == nil {
+		t.Error("loadPackages without -mod=mod: got nil error, want error")
+	}
+
+	cfg = (&Options{BuildFlags: []string{"-mod=mod"}, Env: env}).packagesConfig(dir)
+	pkgs, err := loadPackages(cfg, "example.com/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := pkgs[0].PkgPath, "example.com/a"; got != want {
+		t.Errorf("got package %s, want %s", got, want)
+	}
+}
+
+func TestOptionsVendor(t *testing.T) {
+	for _, test := range []struct {
+		opts *Options
+		want bool
+	}{
+		{&Options{}, false},
+		{&Options{BuildFlags: []string{"-mod=mod"}}, false},
+		{&Options{BuildFlags: []string{"-mod=vendor"}}, true},
+		{&Options{Env: []string{"GOFLAGS=-trimpath -mod=vendor"}}, true},
+		{&Options{BuildFlags: []string{"-mod=mod"}, Env: []string{"GOFLAGS=-mod=vendor"}}, true},
+		{&Options{BuildFlags: []string{"-mod=vendor", "-mod=readonly"}}, false},
+	} {
+		if got := test.opts.vendor(); got != test.want {
+			t.Errorf("%+v.vendor() = %t, want %t", test.opts, got, test.want)
+		}
+	}
+}
+
+func TestCheckGlob(t *testing.T) {
+	for _, test := range []struct {
+		module  string
+		pattern string
+		wantErr bool
+	}{
+		{module: "example.com/m", pattern: "example.com/m/..."},
+		{module: "example.com/m", pattern: "example.com/m/p/..."},
+		{module: "example.com/m", pattern: "example.com/m", wantErr: true},
+		{module: "example.com/m", pattern: "example.com/mm/...", wantErr: true},
+		{module: "example.com/m", pattern: "example.com/...", wantErr: true},
+		{module: "std", pattern: "net/..."},
+		{module: "std", pattern: "cmd/go/...", wantErr: true},
+		{module: "std", pattern: "example.com/m/...", wantErr: true},
+		{module: "cmd", pattern: "cmd/go/..."},
+		{module: "cmd", pattern: "net/...", wantErr: true},
+	} {
+		m := &report.Module{Module: test.module}
+		err := checkGlob(m, test.pattern)
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("checkGlob(%q, %q) = %v, want error: %t", test.module, test.pattern, err, test.wantErr)
+		}
+	}
+}
+
+func TestExportedPlan(t *testing.T) {
+	m := &report.Module{
+		Module:               "example.com/m",
+		VulnerableAt:         "1.2.3",
+		VulnerableAtRequires: []string{"example.com/a@v0.1.0", "example.com/b@v0.2.0"},
+	}
+	p := &report.Package{Package: "example.com/m/p"}
+	got, err := ExportedPlan(m, p, nil, log.New(os.Stderr, "", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(got.GoMod, "module go.dev/_\n") {
+		t.Errorf("GoMod = %q, want module go.dev/_", got.GoMod)
+	}
+	want := &ExtractionPlan{
+		GoMod: got.GoMod,
+		ImportStub: `package p
+import _ "example.com/m/p"
+import _ "example.com/a"
+import _ "example.com/b"
+`,
+		Requires: []string{"example.com/a@v0.1.0", "example.com/b@v0.2.0", "example.com/m@v1.2.3"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestExportedPlanOptions(t *testing.T) {
+	m := &report.Module{
+		Module:       "example.com/m",
+		VulnerableAt: "1.2.3",
+	}
+	p := &report.Package{Package: "example.com/m/p"}
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"go.mod": "module example.com/m\n"})
+	for _, test := range []struct {
+		name string
+		opts *Options
+		want []string // in the go.mod file
+	}{
+		{
+			name: "revision",
+			opts: &Options{Revision: "0.0.0-20230102030405-abcdef123456"},
+			want: []string{"example.com/m v0.0.0-20230102030405-abcdef123456"},
+		},
+		{
+			name: "local",
+			opts: &Options{LocalDir: dir},
+			want: []string{"example.com/m " + localVersion, "replace example.com/m => " + dir},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ExportedPlan(m, p, test.opts, log.New(io.Discard, "", 0))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, w := range test.want {
+				if !strings.Contains(got.GoMod, w) {
+					t.Errorf("GoMod = %q, want it to contain %q", got.GoMod, w)
+				}
+			}
+			if strings.Contains(got.GoMod, "v1.2.3") {
+				t.Errorf("GoMod = %q, want no vulnerable_at version", got.GoMod)
+			}
+		})
+	}
+}
+
+func TestExportedSymbolsEnv(t *testing.T) {
+	// With GOPROXY=off, a module that is not in the module cache
+	// can't be downloaded, so extraction fails, and the go command
+	// reports that it used the given GOPROXY setting.
+	m := &report.Module{
+		Module:       "example.com/not/in/cache",
+		VulnerableAt: "1.0.0",
+		Packages: []*report.Package{{
+			Package: "example.com/not/in/cache/p",
+			Symbols: []string{"F"},
+		}},
+	}
+	opts := &Options{Env: []string{"GOPROXY=off", "GOFLAGS=-mod=mod"}}
+	var buf strings.Builder
+	if _, err := exportedSymbols(m, m.Packages[0], opts, log.New(&buf, "", 0)); err == nil {
+		t.Fatal("got nil error, want error")
+	}
+	if got, want := buf.String(), "GOPROXY=off"; !strings.Contains(got, want) {
+		t.Errorf("go command output does not mention %q:\n%s", want, got)
+	}
+}
+
+func TestOptionsCheckSource(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/m\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		module  string
+		opts    *Options
+		wantErr string
+	}{
+		{module: "example.com/m", opts: &Options{}},
+		{module: "example.com/m", opts: &Options{Revision: "0.0.0-20230102030405-abcdef123456"}},
+		{module: "example.com/m", opts: &Options{Revision: "abcdef123456"}},
+		{module: "example.com/m", opts: &Options{LocalDir: dir}},
+		{
+			module:  "example.com/m",
+			opts:    &Options{Revision: "1.2.3"},
+			wantErr: "not a pseudo-version or commit hash",
+		},
+		{
+			module:  "example.com/m",
+			opts:    &Options{Revision: "abcdef123456", LocalDir: dir},
+			wantErr: "only one of",
+		},
+		{
+			module:  "std",
+			opts:    &Options{Revision: "abcdef123456"},
+			wantErr: "can't be used with module std",
+		},
+		{
+			module:  "example.com/m",
+			opts:    &Options{LocalDir: "m"},
+			wantErr: "not an absolute path",
+		},
+		{
+			module:  "example.com/n",
+			opts:    &Options{LocalDir: dir},
+			wantErr: `contains module "example.com/m"`,
+		},
+		{
+			module:  "example.com/m",
+			opts:    &Options{LocalDir: filepath.Join(dir, "missing")},
+			wantErr: "no such file",
+		},
+	} {
+		err := test.opts.checkSource(&report.Module{Module: test.module})
+		switch {
+		case test.wantErr == "" && err != nil:
+			t.Errorf("%s %+v: got error %v, want nil", test.module, test.opts, err)
+		case test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)):
+			t.Errorf("%s %+v: got error %v, want error containing %q", test.module, test.opts, err, test.wantErr)
+		}
+	}
+}
+
+func TestExportedSymbolsLocalDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod": "module example.com/m\n\ngo 1.18\n",
+		"p/p.go": "package p\n\nfunc Exp() { vuln() }\n\nfunc Other() {}\n\nfunc vuln() {}\n",
+	})
+	// The vulnerable_at version doesn't exist, and isn't used.
+	m := &report.Module{
+		Module:       "example.com/m",
+		VulnerableAt: "9.9.9",
+		Versions:     []report.VersionRange{{Fixed: "1.0.0"}},
+		Packages: []*report.Package{{
+			Package: "example.com/m/p",
+			Symbols: []string{"vuln"},
+		}},
+	}
+	opts := &Options{
+		LocalDir: dir,
+		Env:      []string{"GOPROXY=off", "GOFLAGS=-mod=mod"},
+	}
+	got, err := ExportedSymbols(m, m.Packages[0], opts, log.New(io.Discard, "", 0))
 	if err != nil {
 		t.Fatal(err)
 	}
-	want := map[string]bool{"Exp": true, "Trans": true, "D.Dep": true}
-	if !cmp.Equal(got, want) {
-		t.Errorf("\ngot\n\t%v\nwant\n\t%v", got, want)
+	if diff := cmp.Diff([]*Symbol{{Name: "Exp"}}, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
+
+func TestExportedSymbolsConcurrent(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Two modules with a package of the same name, in which different
+	// exported functions call the vulnerable one.
+	exported := map[string]string{
+		"example.com/a": "A",
+		"example.com/b": "B",
+	}
+	var (
+		modules []*report.Module
+		opts    []*Options
+	)
+	for path, name := range exported {
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{
+			"go.mod": "module " + path + "\n\ngo 1.18\n",
+			"p/p.go": "package p\n\nfunc " + name + "() { vuln() }\n\nfunc vuln() {}\n",
+		})
+		modules = append(modules, &report.Module{
+			Module:       path,
+			VulnerableAt: "9.9.9",
+			Versions:     []report.VersionRange{{Fixed: "1.0.0"}},
+			Packages:     []*report.Package{{Package: path + "/p", Symbols: []string{"vuln"}}},
+		})
+		opts = append(opts, &Options{
+			LocalDir: dir,
+			Env:      []string{"GOPROXY=off", "GOFLAGS=-mod=mod"},
+		})
+	}
+
+	var wg sync.WaitGroup
+	got := make([][]*Symbol, len(modules))
+	errs := make([]error, len(modules))
+	for i := range modules {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got[i], errs[i] = ExportedSymbols(modules[i], modules[i].Packages[0], opts[i], log.New(io.Discard, "", 0))
+		}()
+	}
+	wg.Wait()
+	for i, m := range modules {
+		if errs[i] != nil {
+			t.Errorf("%s: %v", m.Module, errs[i])
+			continue
+		}
+		want := []*Symbol{{Name: exported[m.Module]}}
+		if diff := cmp.Diff(want, got[i]); diff != "" {
+			t.Errorf("%s: mismatch (-want, +got):\n%s", m.Module, diff)
+		}
+	}
+	if dir, err := os.Getwd(); err != nil || dir != cwd {
+		t.Errorf("working directory = %q, %v; want %q", dir, err, cwd)
+	}
+}
+
+// writeFiles writes files, which maps slash-separated paths
+// relative to dir to their contents, creating directories as needed.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
 	}
 }
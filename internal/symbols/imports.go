@@ -0,0 +1,132 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package symbols
+
+import (
+	"context"
+	"log"
+	"sort"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/vulndb/internal/derrors"
+	"golang.org/x/vulndb/internal/report"
+)
+
+// RedundantPackages returns the paths of the packages of module m that
+// could be omitted from the imports of its OSV entry, in sorted order.
+//
+// A package is redundant if each of its vulnerable symbols (listed or
+// derived) calls, directly or indirectly, a vulnerable symbol of
+// another package of m. A user of the package is then found to be
+// affected through that other package by call graph analysis, such as
+// that of govulncheck in source mode. Packages that are vulnerable as a
+// whole (with no symbols listed) are never redundant. Binary analysis
+// can only see the symbols that remain in a binary after inlining, so
+// the full set of packages should still be published by default.
+//
+// The packages are loaded at m's vulnerable_at version, together,
+// since the calls between them are needed. The Cache and Refresh
+// fields of opts are not used.
+func RedundantPackages(m *report.Module, opts *Options, errlog *log.Logger) (_ []string, err error) {
+	defer derrors.Wrap(&err, "RedundantPackages(%q)", m.Module)
+
+	if opts == nil {
+		opts = &Options{}
+	}
+	if err := opts.checkSource(m); err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, p := range m.Packages {
+		paths = append(paths, p.Package)
+	}
+	if len(paths) < 2 {
+		return nil, nil
+	}
+
+	dir, cleanup, err := makeTempDir()
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	if err := initModule(dir, m, opts, errlog); err != nil {
+		return nil, err
+	}
+	if err := requirePackages(dir, m, paths, opts.Env, errlog); err != nil {
+		return nil, err
+	}
+	pkgs, err := loadPackages(opts.packagesConfig(dir), paths...)
+	if err != nil {
+		return nil, err
+	}
+	return redundantPackages(pkgs, m)
+}
+
+// redundantPackages implements RedundantPackages for the loaded pkgs,
+// which must include the packages of m.
+func redundantPackages(pkgs []*packages.Package, m *report.Module) ([]string, error) {
+	prog, ssaPkgs := buildSSA(pkgs, pkgs[0].Fset)
+	cg, err := callGraph(context.Background(), prog, entryPoints(ssaPkgs))
+	if err != nil {
+		return nil, err
+	}
+	sinks := make(map[string][]*callgraph.Node)
+	for _, n := range vulnFuncs(cg, m, true) {
+		p := pkgPath(n.Func)
+		sinks[p] = append(sinks[p], n)
+	}
+
+	// Packages are only considered covered by packages that are kept,
+	// so that two packages that call each other (for example, through
+	// an interface) are not both dropped.
+	dropped := make(map[string]bool)
+	for _, p := range m.Packages {
+		syms := p.AllSymbols()
+		if len(syms) == 0 {
+			continue
+		}
+		others := make(map[*callgraph.Node]bool)
+		for path, nodes := range sinks {
+			if path == p.Package || dropped[path] {
+				continue
+			}
+			for _, n := range nodes {
+				others[n] = true
+			}
+		}
+		// A symbol that isn't in the call graph (for example, because
+		// no exported function calls it) can't be shown to be covered
+		// by another package.
+		found := make(map[string]bool)
+		uncovered := make(map[string]bool)
+		for _, n := range sinks[p.Package] {
+			name := dbFuncName(n.Func)
+			found[name] = true
+			if !reachesAny(n, others) {
+				uncovered[name] = true
+			}
+		}
+		if allCovered(syms, found, uncovered) {
+			dropped[p.Package] = true
+		}
+	}
+	redundant := maps.Keys(dropped)
+	sort.Strings(redundant)
+	return redundant, nil
+}
+
+// allCovered reports whether every symbol in syms is found,
+// and not uncovered.
+func allCovered(syms []string, found, uncovered map[string]bool) bool {
+	for _, s := range syms {
+		if !found[s] || uncovered[s] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package symbols
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/go/packages/packagestest"
+	"golang.org/x/vulndb/internal/report"
+)
+
+func TestRedundantPackages(t *testing.T) {
+	e := packagestest.Export(t, packagestest.Modules, []packagestest.Module{
+		{
+			Name: "example.com/m",
+			Files: map[string]interface{}{
+				"p/p.go": `
+					package p
+
+					func Vuln() {}
+				`,
+				// All the symbols of q call p.Vuln.
+				"q/q.go": `
+					package q
+
+					import "example.com/m/p"
+
+					func Wrap() { p.Vuln() }
+					func Wrap2() { Wrap() }
+				`,
+				// s has vulnerable code of its own.
+				"s/s.go": `
+					package s
+
+					import "example.com/m/p"
+
+					func Own() {}
+					func Wrap() { p.Vuln() }
+				`,
+				// w is vulnerable as a whole.
+				"w/w.go": `
+					package w
+
+					import "example.com/m/p"
+
+					func Wrap() { p.Vuln() }
+				`,
+			},
+		},
+	})
+	defer e.Cleanup()
+
+	m := &report.Module{
+		Module: "example.com/m",
+		Packages: []*report.Package{
+			{Package: "example.com/m/p", Symbols: []string{"Vuln"}},
+			{Package: "example.com/m/q", Symbols: []string{"Wrap"}, DerivedSymbols: []string{"Wrap2"}},
+			{Package: "example.com/m/s", Symbols: []string{"Own"}, DerivedSymbols: []string{"Wrap"}},
+			{Package: "example.com/m/w"},
+		},
+	}
+	var paths []string
+	for _, p := range m.Packages {
+		paths = append(paths, p.Package)
+	}
+	pkgs, err := loadPackages(e.Config, paths...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := redundantPackages(pkgs, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"example.com/m/q"}, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package symbols
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/vulndb/internal/derrors"
+	"golang.org/x/vulndb/internal/proxy"
+	"golang.org/x/vulndb/internal/report"
+)
+
+// ExportedMajorVersions derives the vulnerable symbols exported by the
+// packages of each major version of the module with path base, such as
+// example.com/foo (for v0 and v1), example.com/foo/v2 and so on.
+//
+// The major versions are discovered on the proxy, starting with v2 and
+// stopping at the first one that doesn't exist. Each major version is a
+// separate module, so only those that r lists as modules are analyzed,
+// with their own versions and vulnerable_at; the others are logged to
+// errlog. Packages that are skipped, for example because the
+// vulnerable_at version of their module is not affected, are omitted.
+//
+// The result maps module paths to package paths to the symbols derived
+// for each package, in the form of ExportedSymbols.
+func ExportedMajorVersions(r *report.Report, base string, pc *proxy.Client, opts *Options, errlog *log.Logger) (_ map[string]map[string][]string, err error) {
+	defer derrors.Wrap(&err, "ExportedMajorVersions(%q)", base)
+
+	paths, err := majorVersionPaths(pc, base)
+	if err != nil {
+		return nil, err
+	}
+	modules := make(map[string]*report.Module)
+	for _, m := range r.Modules {
+		modules[m.Module] = m
+	}
+	result := make(map[string]map[string][]string)
+	for _, path := range paths {
+		m := modules[path]
+		if m == nil {
+			errlog.Printf("module %s exists on the proxy, but is not listed in the report; skipping\n", path)
+			continue
+		}
+		if err := checkMajorVersion(m); err != nil {
+			return nil, err
+		}
+		for _, p := range m.Packages {
+			res, err := ExportedResult(m, p, opts, errlog)
+			if err != nil {
+				return nil, err
+			}
+			if res.Skip != NotSkipped {
+				continue
+			}
+			if result[path] == nil {
+				result[path] = make(map[string][]string)
+			}
+			var names []string
+			for _, s := range res.Symbols {
+				names = append(names, s.Name)
+			}
+			result[path][p.Package] = names
+		}
+	}
+	return result, nil
+}
+
+// majorVersionPaths returns the paths of the major versions of the
+// module with path base that exist on the proxy, in order.
+//
+// The base path must not have a major version suffix, and must not be
+// a gopkg.in path, whose major versions are part of the import path in
+// a different form.
+func majorVersionPaths(pc *proxy.Client, base string) ([]string, error) {
+	if err := module.CheckPath(base); err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(base, "gopkg.in/") {
+		return nil, fmt.Errorf("gopkg.in module %s is not supported", base)
+	}
+	if _, pathMajor, _ := module.SplitPathVersion(base); pathMajor != "" {
+		return nil, fmt.Errorf("module path %s has a major version suffix; want the path without %s", base, pathMajor)
+	}
+	var paths []string
+	exists := func(path string) (bool, error) {
+		_, err := pc.Versions(path)
+		if errors.Is(err, proxy.ErrUnavailable) {
+			return false, err
+		}
+		return err == nil, nil
+	}
+	ok, err := exists(base)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		paths = append(paths, base)
+	}
+	for n := 2; ; n++ {
+		path := fmt.Sprintf("%s/v%d", base, n)
+		ok, err := exists(path)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		paths = append(paths, path)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no major versions of %s found on the proxy", base)
+	}
+	return paths, nil
+}
+
+// checkMajorVersion checks that the versions of m, including its
+// vulnerable_at version, have the major version of its path. For
+// example, the versions of example.com/foo/v2 must be v2 versions,
+// and the versions of example.com/foo must be v0 or v1 versions, or
+// +incompatible.
+func checkMajorVersion(m *report.Module) error {
+	_, pathMajor, ok := module.SplitPathVersion(m.Module)
+	if !ok {
+		return fmt.Errorf("invalid module path %s", m.Module)
+	}
+	vs := []string{m.VulnerableAt}
+	for _, vr := range m.Versions {
+		vs = append(vs, vr.Introduced, vr.Fixed)
+	}
+	for _, v := range vs {
+		if v == "" {
+			continue
+		}
+		if err := module.CheckPathMajor("v"+v, pathMajor); err != nil {
+			return fmt.Errorf("module %s: %v", m.Module, err)
+		}
+	}
+	return nil
+}
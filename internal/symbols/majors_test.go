@@ -0,0 +1,104 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package symbols
+
+import (
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vulndb/internal/proxy"
+	"golang.org/x/vulndb/internal/report"
+)
+
+func TestMajorVersionPaths(t *testing.T) {
+	pc, err := proxy.NewTestClient(t, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		base    string
+		want    []string
+		wantErr string
+	}{
+		{
+			base: "example.com/foo",
+			want: []string{"example.com/foo", "example.com/foo/v2"},
+		},
+		{
+			base:    "example.com/foo/v2",
+			wantErr: "has a major version suffix",
+		},
+		{
+			base:    "gopkg.in/yaml.v3",
+			wantErr: "not supported",
+		},
+		{
+			base:    "example.com/bar",
+			wantErr: "no major versions",
+		},
+	} {
+		got, err := majorVersionPaths(pc, test.base)
+		if test.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("%s: got error %v, want error containing %q", test.base, err, test.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(test.want, got); diff != "" {
+			t.Errorf("%s: mismatch (-want, +got):\n%s", test.base, diff)
+		}
+	}
+}
+
+func TestCheckMajorVersion(t *testing.T) {
+	for _, test := range []struct {
+		module       string
+		versions     []report.VersionRange
+		vulnerableAt string
+		wantErr      bool
+	}{
+		{module: "example.com/foo", versions: []report.VersionRange{{Introduced: "0.5.0", Fixed: "1.2.0"}}, vulnerableAt: "1.1.0"},
+		{module: "example.com/foo", versions: []report.VersionRange{{Fixed: "2.0.0+incompatible"}}},
+		{module: "example.com/foo/v2", versions: []report.VersionRange{{Introduced: "2.0.0", Fixed: "2.3.1"}}, vulnerableAt: "2.3.0"},
+		{module: "example.com/foo", versions: []report.VersionRange{{Fixed: "2.1.0"}}, wantErr: true},
+		{module: "example.com/foo/v2", versions: []report.VersionRange{{Fixed: "3.0.0"}}, wantErr: true},
+		{module: "example.com/foo/v2", vulnerableAt: "1.9.0", wantErr: true},
+	} {
+		m := &report.Module{Module: test.module, Versions: test.versions, VulnerableAt: test.vulnerableAt}
+		err := checkMajorVersion(m)
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("%s %v: got error %v, want error: %t", test.module, test.versions, err, test.wantErr)
+		}
+	}
+}
+
+func TestExportedMajorVersionsUnlisted(t *testing.T) {
+	pc, err := proxy.NewTestClient(t, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Neither major version is listed in the report, so no packages
+	// are loaded.
+	r := &report.Report{Modules: []*report.Module{{Module: "example.com/other"}}}
+	var buf strings.Builder
+	got, err := ExportedMajorVersions(r, "example.com/foo", pc, nil, log.New(&buf, "", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("got %v, want no results", got)
+	}
+	want := `module example.com/foo exists on the proxy, but is not listed in the report; skipping
+module example.com/foo/v2 exists on the proxy, but is not listed in the report; skipping
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("log mismatch (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package symbols
+
+import (
+	"fmt"
+	"log"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/vulndb/internal/derrors"
+	"golang.org/x/vulndb/internal/report"
+	"golang.org/x/vulndb/internal/stdlib"
+)
+
+// PublicPackages finds the public entry points to the vulnerable
+// symbols of package p of module m, for packages such as internal ones
+// that can't be imported from outside m. Exported derives nothing useful
+// for them, since it only considers the functions of p itself.
+//
+// The result maps the path of each importable package of m to the
+// exported functions and methods that can reach any of the symbols of
+// p, in sorted order. These are the symbols through which users of m
+// are affected, and the packages can be added to the report in place
+// of p (or alongside it).
+//
+// All the packages of m are loaded at its vulnerable_at version, so
+// this may be slow for large modules. For the standard library, the
+// whole standard library of the Go toolchain in use is loaded, as by
+// StdlibPackages. The Cache and Refresh fields of opts are not used.
+func PublicPackages(m *report.Module, p *report.Package, opts *Options, errlog *log.Logger) (_ map[string][]string, err error) {
+	defer derrors.Wrap(&err, "PublicPackages(%q, %q)", m.Module, p.Package)
+
+	if opts == nil {
+		opts = &Options{}
+	}
+	if isImportable(p.Package) {
+		return nil, fmt.Errorf("package %s can be imported; use Exported", p.Package)
+	}
+	if len(p.Symbols) == 0 {
+		return nil, fmt.Errorf("package %s has no symbols", p.Package)
+	}
+	var pkgs []*packages.Package
+	switch {
+	case m.Module == stdlib.ModulePath:
+		pkgs, err = loadPackages(opts.packagesConfig(""), "std")
+	case m.IsFirstParty():
+		return nil, fmt.Errorf("module %s is not supported", m.Module)
+	default:
+		pkgs, err = loadGlob(m, m.Module+"/...", opts, errlog)
+	}
+	if err != nil {
+		return nil, err
+	}
+	result, err := reachingPackages(pkgs, m.Module, p.Package, p.Symbols)
+	if err != nil {
+		return nil, err
+	}
+	if !m.IsFirstParty() {
+		// Nested modules are separate modules, which are not
+		// affected by this report.
+		for _, pkg := range pkgs {
+			if pkg.Module == nil || pkg.Module.Path != m.Module {
+				delete(result, pkg.PkgPath)
+			}
+		}
+	}
+	return result, nil
+}
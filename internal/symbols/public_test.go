@@ -0,0 +1,76 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package symbols
+
+import (
+	"io"
+	"log"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vulndb/internal/report"
+)
+
+func TestPublicPackages(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod": "module example.com/m\n\ngo 1.18\n",
+		"internal/parse/parse.go": `package parse
+
+func Parse() { vuln() }
+
+func vuln() {}
+`,
+		"internal/wrap/wrap.go": `package wrap
+
+import "example.com/m/internal/parse"
+
+func Wrap() { parse.Parse() }
+`,
+		"m.go": `package m
+
+import "example.com/m/internal/parse"
+
+type Decoder struct{}
+
+func (*Decoder) Decode() { parse.Parse() }
+
+func Safe() {}
+`,
+		"api/api.go": `package api
+
+import "example.com/m/internal/wrap"
+
+func Load() { wrap.Wrap() }
+`,
+		"other/other.go": "package other\n\nfunc F() {}\n",
+	})
+	m := &report.Module{
+		Module:       "example.com/m",
+		VulnerableAt: "9.9.9",
+		Versions:     []report.VersionRange{{Fixed: "1.0.0"}},
+	}
+	opts := &Options{
+		LocalDir: dir,
+		Env:      []string{"GOPROXY=off", "GOFLAGS=-mod=mod"},
+	}
+	p := &report.Package{Package: "example.com/m/internal/parse", Symbols: []string{"vuln"}}
+	got, err := PublicPackages(m, p, opts, log.New(io.Discard, "", 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{
+		"example.com/m":     {"Decoder.Decode"},
+		"example.com/m/api": {"Load"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	public := &report.Package{Package: "example.com/m/api", Symbols: []string{"Load"}}
+	if _, err := PublicPackages(m, public, opts, log.New(io.Discard, "", 0)); err == nil {
+		t.Error("got nil error for importable package, want error")
+	}
+}
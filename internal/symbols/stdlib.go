@@ -0,0 +1,91 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package symbols
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/vulndb/internal/derrors"
+	"golang.org/x/vulndb/internal/report"
+	"golang.org/x/vulndb/internal/stdlib"
+)
+
+// StdlibPackages suggests additional standard library packages that are
+// affected by a vulnerable symbol in the standard library package
+// pkgPath, for example a package that wraps the vulnerable function.
+//
+// The result maps the path of each other package with exported functions
+// that can reach the symbol to the names of those functions, in sorted
+// order. Internal and vendored packages are omitted, as they cannot be
+// imported by users.
+//
+// StdlibPackages loads and analyzes the whole standard library of the
+// Go toolchain in use, so it may be slow. It only makes suggestions, and
+// doesn't modify any report. The Cache and Refresh fields of opts are
+// not used.
+func StdlibPackages(pkgPath, symbol string, opts *Options) (_ map[string][]string, err error) {
+	defer derrors.Wrap(&err, "StdlibPackages(%q, %q)", pkgPath, symbol)
+
+	if opts == nil {
+		opts = &Options{}
+	}
+	pkgs, err := loadPackages(opts.packagesConfig(""), "std")
+	if err != nil {
+		return nil, err
+	}
+	return reachingPackages(pkgs, stdlib.ModulePath, pkgPath, []string{symbol})
+}
+
+// reachingPackages returns the exported functions of the importable
+// packages in pkgs, other than vulnPkg, that can reach any of symbols
+// in vulnPkg, keyed by package path. modulePath is the module containing
+// vulnPkg.
+func reachingPackages(pkgs []*packages.Package, modulePath, vulnPkg string, symbols []string) (map[string][]string, error) {
+	found := false
+	for _, p := range pkgs {
+		if p.PkgPath == vulnPkg {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("package %s not loaded", vulnPkg)
+	}
+	m := &report.Module{
+		Module:   modulePath,
+		Packages: []*report.Package{{Package: vulnPkg, Symbols: symbols}},
+	}
+	entries, _, _, err := vulnEntries(pkgs, m, nil)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string][]string)
+	for _, e := range entries {
+		p := pkgPath(e)
+		// As in newSymbols, init functions are not considered.
+		if p == vulnPkg || !isImportable(p) || e.Name() == "init" {
+			continue
+		}
+		result[p] = append(result[p], ssaSymbolName(e))
+	}
+	for _, names := range result {
+		sort.Strings(names)
+	}
+	return result, nil
+}
+
+// isImportable reports whether the package at path can be imported
+// from outside the module, that is, it is not internal or vendored.
+func isImportable(path string) bool {
+	for _, elem := range strings.Split(path, "/") {
+		if elem == "internal" || elem == "vendor" {
+			return false
+		}
+	}
+	return true
+}
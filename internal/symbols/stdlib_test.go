@@ -0,0 +1,81 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package symbols
+
+import (
+	"path"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/go/packages/packagestest"
+)
+
+func TestReachingPackages(t *testing.T) {
+	e := packagestest.Export(t, packagestest.Modules, []packagestest.Module{
+		{
+			Name: "example.com/m",
+			Files: map[string]interface{}{
+				"tls/tls.go": `
+					package tls
+
+					func Vuln() {}
+					func Dial() { Vuln() }
+				`,
+				"http/http.go": `
+					package http
+
+					import "example.com/m/tls"
+
+					type Transport struct{}
+					func (*Transport) RoundTrip() { get() }
+					func Get() { get() }
+					func Safe() {}
+					func get() { tls.Dial() }
+				`,
+				"internal/wrap/wrap.go": `
+					package wrap
+
+					import "example.com/m/tls"
+
+					func Wrap() { tls.Vuln() }
+				`,
+				"smtp/smtp.go": `
+					package smtp
+
+					import "example.com/m/internal/wrap"
+
+					func Send() { wrap.Wrap() }
+				`,
+				"other/other.go": `
+					package other
+
+					func F() {}
+				`,
+			},
+		},
+	})
+	defer e.Cleanup()
+
+	e.Config.Dir = path.Join(e.Temp(), "m")
+	pkgs, err := loadPackages(e.Config, "./...")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := reachingPackages(pkgs, "example.com/m", "example.com/m/tls", []string{"Vuln"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{
+		"example.com/m/http": {"Get", "Transport.RoundTrip"},
+		"example.com/m/smtp": {"Send"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	if _, err := reachingPackages(pkgs, "example.com/m", "example.com/m/missing", []string{"Vuln"}); err == nil {
+		t.Error("got nil error for missing package, want error")
+	}
+}
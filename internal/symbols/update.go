@@ -0,0 +1,187 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package symbols
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+	"golang.org/x/vulndb/internal/derrors"
+	"golang.org/x/vulndb/internal/report"
+)
+
+// UpdateOptions configures UpdateSymbols.
+// A nil *UpdateOptions is equivalent to the zero value.
+type UpdateOptions struct {
+	// Options are used to derive the symbols of each package.
+	Options
+	// RemoveStale causes derived symbols that are no longer derived
+	// to be removed. By default, they are kept.
+	RemoveStale bool
+}
+
+// UpdateSymbols derives the vulnerable symbols of each package of r
+// that has symbols, and merges them into the package's DerivedSymbols.
+// It reports whether any package's DerivedSymbols changed.
+//
+// Excluded reports, and packages with a skip_fix reason, are ignored.
+// Derived symbols that are already present keep their order, and new
+// ones are added after them.
+func UpdateSymbols(r *report.Report, opts *UpdateOptions, errlog *log.Logger) (changed bool, err error) {
+	if opts == nil {
+		opts = &UpdateOptions{}
+	}
+	return updateSymbols(r, opts.RemoveStale, func(m *report.Module, p *report.Package) ([]string, error) {
+		syms, err := ExportedSymbols(m, p, &opts.Options, errlog)
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, s := range syms {
+			names = append(names, s.Name)
+		}
+		return names, nil
+	})
+}
+
+// RegenerateAllSymbols runs UpdateSymbols on each of reports, which
+// are keyed by an arbitrary name such as their filename, using at most
+// concurrency workers at a time (at least one). It logs the progress
+// to errlog as each report is done, and returns the keys of the reports
+// whose derived symbols changed, in sorted order. The caller is
+// responsible for writing them.
+//
+// A failure to update one report doesn't stop the others from being
+// updated. If any failed, the returned error lists all of them, and
+// the changed reports are still returned. (A report that failed may
+// have been partially updated, and is listed as changed if so.)
+func RegenerateAllSymbols(reports map[string]*report.Report, concurrency int, opts *UpdateOptions, errlog *log.Logger) (changed []string, err error) {
+	return regenerateAll(reports, concurrency, errlog, func(r *report.Report) (bool, error) {
+		return UpdateSymbols(r, opts, errlog)
+	})
+}
+
+// regenerateAll implements RegenerateAllSymbols, using update to
+// update each report.
+func regenerateAll(reports map[string]*report.Report, concurrency int, errlog *log.Logger, update func(*report.Report) (bool, error)) (changed []string, err error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	keys := maps.Keys(reports)
+	sort.Strings(keys)
+
+	type result struct {
+		key     string
+		changed bool
+		err     error
+	}
+	work := make(chan string)
+	results := make(chan result)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range work {
+				changed, err := update(reports[key])
+				results <- result{key, changed, err}
+			}
+		}()
+	}
+	go func() {
+		for _, key := range keys {
+			work <- key
+		}
+		close(work)
+		wg.Wait()
+		close(results)
+	}()
+
+	failed := make(map[string]error)
+	done := 0
+	for res := range results {
+		done++
+		status := "up to date"
+		switch {
+		case res.err != nil:
+			failed[res.key] = res.err
+			status = "failed"
+		case res.changed:
+			status = "changed"
+		}
+		if res.changed {
+			changed = append(changed, res.key)
+		}
+		errlog.Printf("[%d/%d] %s: %s\n", done, len(keys), res.key, status)
+	}
+	sort.Strings(changed)
+	if len(failed) > 0 {
+		return changed, regenerateError(failed, len(keys))
+	}
+	return changed, nil
+}
+
+// regenerateError returns an error summarizing the errors in failed,
+// which are keyed by report, out of total reports.
+func regenerateError(failed map[string]error, total int) error {
+	keys := maps.Keys(failed)
+	sort.Strings(keys)
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d of %d reports failed:", len(failed), total)
+	for _, key := range keys {
+		fmt.Fprintf(&b, "\n\t%s: %v", key, failed[key])
+	}
+	return errors.New(b.String())
+}
+
+// updateSymbols implements UpdateSymbols, using extract to derive
+// the symbols of a package.
+func updateSymbols(r *report.Report, removeStale bool, extract func(*report.Module, *report.Package) ([]string, error)) (changed bool, err error) {
+	defer derrors.Wrap(&err, "UpdateSymbols(%q)", r.ID)
+
+	if r.IsExcluded() {
+		return false, nil
+	}
+	for _, m := range r.Modules {
+		for _, p := range m.Packages {
+			if len(p.Symbols) == 0 || p.SkipFix != "" {
+				continue
+			}
+			derived, err := extract(m, p)
+			if err != nil {
+				return changed, fmt.Errorf("package %s: %w", p.Package, err)
+			}
+			if merged := mergeSymbols(p.DerivedSymbols, derived, removeStale); !slices.Equal(merged, p.DerivedSymbols) {
+				p.DerivedSymbols = merged
+				changed = true
+			}
+		}
+	}
+	return changed, nil
+}
+
+// mergeSymbols returns the symbols in old followed by the symbols in
+// derived that are not in old. If removeStale is true, the symbols in
+// old that are not in derived are omitted.
+func mergeSymbols(old, derived []string, removeStale bool) []string {
+	var merged []string
+	for _, s := range old {
+		if !removeStale || slices.Contains(derived, s) {
+			merged = append(merged, s)
+		}
+	}
+	for _, s := range derived {
+		if !slices.Contains(merged, s) {
+			merged = append(merged, s)
+		}
+	}
+	return merged
+}
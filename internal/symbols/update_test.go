@@ -0,0 +1,164 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package symbols
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/vulndb/internal/report"
+)
+
+func TestUpdateSymbols(t *testing.T) {
+	// fakeExtract derives the symbols in derived for each package,
+	// and records the packages it was called for.
+	var called []string
+	derived := map[string][]string{
+		"example.com/m/p": {"A", "B"},
+		"example.com/m/q": {"C"},
+	}
+	fakeExtract := func(_ *report.Module, p *report.Package) ([]string, error) {
+		called = append(called, p.Package)
+		return derived[p.Package], nil
+	}
+	newReport := func() *report.Report {
+		return &report.Report{
+			ID: "GO-0000-0000",
+			Modules: []*report.Module{{
+				Module: "example.com/m",
+				Packages: []*report.Package{
+					{Package: "example.com/m/p", Symbols: []string{"a"}, DerivedSymbols: []string{"B", "Stale"}},
+					{Package: "example.com/m/q", Symbols: []string{"c"}, DerivedSymbols: []string{"C"}},
+					{Package: "example.com/m/r"}, // no symbols
+					{Package: "example.com/m/s", Symbols: []string{"s"}, SkipFix: "reason"},
+				},
+			}},
+		}
+	}
+
+	for _, test := range []struct {
+		desc        string
+		removeStale bool
+		wantChanged bool
+		wantP       []string
+	}{
+		{
+			desc:        "add",
+			wantChanged: true,
+			wantP:       []string{"B", "Stale", "A"},
+		},
+		{
+			desc:        "add and remove stale",
+			removeStale: true,
+			wantChanged: true,
+			wantP:       []string{"B", "A"},
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			called = nil
+			r := newReport()
+			changed, err := updateSymbols(r, test.removeStale, fakeExtract)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed != test.wantChanged {
+				t.Errorf("changed = %t, want %t", changed, test.wantChanged)
+			}
+			if diff := cmp.Diff([]string{"example.com/m/p", "example.com/m/q"}, called); diff != "" {
+				t.Errorf("packages extracted mismatch (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(test.wantP, r.Modules[0].Packages[0].DerivedSymbols); diff != "" {
+				t.Errorf("p derived symbols mismatch (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff([]string{"C"}, r.Modules[0].Packages[1].DerivedSymbols); diff != "" {
+				t.Errorf("q derived symbols mismatch (-want, +got):\n%s", diff)
+			}
+
+			// A second update is a no-op.
+			changed, err = updateSymbols(r, test.removeStale, fakeExtract)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed {
+				t.Error("second update: changed = true, want false")
+			}
+		})
+	}
+
+	t.Run("excluded", func(t *testing.T) {
+		called = nil
+		r := newReport()
+		r.Excluded = "NOT_IMPORTABLE"
+		changed, err := updateSymbols(r, true, fakeExtract)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if changed || len(called) > 0 {
+			t.Errorf("changed = %t, extracted %v; want no changes or extraction", changed, called)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		errExtract := errors.New("extraction failed")
+		_, err := updateSymbols(newReport(), false, func(*report.Module, *report.Package) ([]string, error) {
+			return nil, errExtract
+		})
+		if !errors.Is(err, errExtract) {
+			t.Errorf("got error %v, want %v", err, errExtract)
+		}
+	})
+}
+
+func TestRegenerateAll(t *testing.T) {
+	reports := make(map[string]*report.Report)
+	for i := 0; i < 10; i++ {
+		id := fmt.Sprintf("GO-0000-%04d", i)
+		reports[id+".yaml"] = &report.Report{ID: id}
+	}
+	var (
+		mu      sync.Mutex
+		updated []string
+	)
+	update := func(r *report.Report) (bool, error) {
+		mu.Lock()
+		updated = append(updated, r.ID)
+		mu.Unlock()
+		switch r.ID {
+		case "GO-0000-0003", "GO-0000-0007":
+			return true, nil
+		case "GO-0000-0005":
+			return false, errors.New("load failed")
+		}
+		return false, nil
+	}
+
+	var buf bytes.Buffer
+	changed, err := regenerateAll(reports, 3, log.New(&buf, "", 0), update)
+	wantErr := "1 of 10 reports failed:\n\tGO-0000-0005.yaml: load failed"
+	if err == nil || err.Error() != wantErr {
+		t.Errorf("got error %v, want %q", err, wantErr)
+	}
+	if diff := cmp.Diff([]string{"GO-0000-0003.yaml", "GO-0000-0007.yaml"}, changed); diff != "" {
+		t.Errorf("changed mismatch (-want, +got):\n%s", diff)
+	}
+	if len(updated) != len(reports) {
+		t.Errorf("updated %d reports, want %d", len(updated), len(reports))
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(reports) || !strings.HasPrefix(lines[len(lines)-1], "[10/10] ") {
+		t.Errorf("got progress log\n%s\nwant one line per report, ending with [10/10]", buf.String())
+	}
+	for _, want := range []string{"GO-0000-0003.yaml: changed", "GO-0000-0005.yaml: failed", "GO-0000-0000.yaml: up to date"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("progress log does not contain %q:\n%s", want, buf.String())
+		}
+	}
+}
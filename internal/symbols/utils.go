@@ -32,12 +32,26 @@ import (
 func loadPackage(cfg *packages.Config, importPath string) (_ *packages.Package, err error) {
 	defer derrors.Wrap(&err, "loadPackage(%s)", importPath)
 
+	pkgs, err := loadPackages(cfg, importPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) > 1 {
+		return nil, fmt.Errorf("multiple (%d) packages found for import path %s", len(pkgs), importPath)
+	}
+
+	return pkgs[0], nil
+}
+
+// loadPackages loads the packages matching the given patterns, with enough
+// information for constructing a call graph.
+func loadPackages(cfg *packages.Config, patterns ...string) ([]*packages.Package, error) {
 	cfg.Mode |= packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles |
 		packages.NeedImports | packages.NeedTypes | packages.NeedTypesSizes |
 		packages.NeedSyntax | packages.NeedTypesInfo | packages.NeedDeps |
 		packages.NeedModule
-	cfg.BuildFlags = []string{fmt.Sprintf("-tags=%s", strings.Join(build.Default.BuildTags, ","))}
-	pkgs, err := packages.Load(cfg, importPath)
+	cfg.BuildFlags = append(cfg.BuildFlags, fmt.Sprintf("-tags=%s", strings.Join(build.Default.BuildTags, ",")))
+	pkgs, err := packages.Load(cfg, patterns...)
 	if err != nil {
 		return nil, err
 	}
@@ -49,11 +63,8 @@ func loadPackage(cfg *packages.Config, importPath string) (_ *packages.Package,
 	if len(pkgs) == 0 {
 		return nil, errors.New("no packages found")
 	}
-	if len(pkgs) > 1 {
-		return nil, fmt.Errorf("multiple (%d) packages found for import path %s", len(pkgs), importPath)
-	}
 
-	return pkgs[0], nil
+	return pkgs, nil
 }
 
 // packageLoadingError returns an error summarizing packages.Package.Errors if there were any.
@@ -258,22 +269,15 @@ func pkgPath(f *ssa.Function) string {
 	return ""
 }
 
-func changeToTempDir() (cleanup func(), _ error) {
-	cwd, err := os.Getwd()
-	if err != nil {
-		return nil, err
-	}
+// makeTempDir creates a temporary directory in which to build a module
+// for loading packages, and returns it with a function that removes it.
+// Commands are run in the directory by passing it to command, rather
+// than by changing the working directory of the process, so that
+// several directories can be used concurrently.
+func makeTempDir() (dir string, cleanup func(), _ error) {
 	dir, err := os.MkdirTemp("", "vulnreport")
 	if err != nil {
-		return nil, err
-	}
-	cleanup = func() {
-		_ = os.RemoveAll(dir)
-		_ = os.Chdir(cwd)
-	}
-	if err := os.Chdir(dir); err != nil {
-		cleanup()
-		return nil, err
+		return "", nil, err
 	}
-	return cleanup, err
+	return dir, func() { _ = os.RemoveAll(dir) }, nil
 }
@@ -8,7 +8,10 @@ import (
 	"context"
 	"fmt"
 	"go/token"
+	"io"
+	"sort"
 
+	"golang.org/x/exp/slices"
 	"golang.org/x/tools/go/callgraph"
 	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/go/ssa"
@@ -16,11 +19,34 @@ import (
 )
 
 // vulnEntries returns entries of pkgs call graph that lead to
-// vulnerable symbols in m.
+// vulnerable symbols in m, and the vulnerable functions of the call
+// graph that they lead to. If debug is non-nil, the SSA of pkgs and
+// of the functions on the paths from the entries to the vulnerable
+// functions is written to it.
+//
+// Every entry returned has a path to a symbol listed in m. Entries
+// that lead only to derived symbols of m, which may be stale or
+// mistaken, are returned separately as unlisted.
 //
 // It assumes that the modules in m present in pkgs, if any,
 // are at a version deemed vulnerable by m.
-func vulnEntries(pkgs []*packages.Package, m *report.Module) ([]*ssa.Function, error) {
+//
+// The analysis follows golang.org/x/vuln/internal/vulncheck, so that
+// derived symbols agree with what govulncheck reports to users (see
+// testdata/vulncheck.txtar, which has results captured from
+// govulncheck for a small module):
+//   - the entries are the exported functions and methods of pkgs
+//     (see entryPoints);
+//   - the call graph is a CHA graph refined by two rounds of VTA,
+//     each restricted to the functions forward reachable from the
+//     entries (see callGraph);
+//   - the result is the set of entries backwards reachable in that
+//     graph from the symbols of m.
+//
+// The vulncheck package is internal to golang.org/x/vuln and cannot be
+// imported, so the relevant parts are copied into this package. Changes
+// to the vulncheck algorithm should be mirrored here.
+func vulnEntries(pkgs []*packages.Package, m *report.Module, debug io.Writer) (entries, reached, unlisted []*ssa.Function, err error) {
 	ctx := context.Background()
 
 	// The following code block is copied from
@@ -31,31 +57,137 @@ func vulnEntries(pkgs []*packages.Package, m *report.Module) ([]*ssa.Function, e
 			fset = p.Fset
 		} else {
 			if fset != p.Fset {
-				return nil, fmt.Errorf("[]*Package must have created with the same FileSet")
+				return nil, nil, nil, fmt.Errorf("[]*Package must have created with the same FileSet")
 			}
 		}
 	}
 	prog, ssaPkgs := buildSSA(pkgs, fset)
-	entries := entryPoints(ssaPkgs)
-	cg, err := callGraph(ctx, prog, entries)
+	allEntries := entryPoints(ssaPkgs)
+	cg, err := callGraph(ctx, prog, allEntries)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 
 	// Identify vulnerable functions/methods in the call graph and
 	// compute the backwards reachable entries.
-	entryNodes := vulnReachingEntries(cg, vulnFuncs(cg, m), entries)
-	var vres []*ssa.Function
+	sinks := vulnFuncs(cg, m, true)
+	entryNodes := vulnReachingEntries(cg, sinks, allEntries)
+	if debug != nil {
+		if err := writeSSA(debug, ssaPkgs, entryNodes, sinks); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	// Check that each entry genuinely leads to a listed symbol, and
+	// not merely to a derived one.
+	toListed := reachable(vulnFuncs(cg, m, false), false)
+	var listedNodes []*callgraph.Node
 	for _, n := range entryNodes {
-		vres = append(vres, n.Func)
+		if !toListed[n] {
+			unlisted = append(unlisted, n.Func)
+			continue
+		}
+		listedNodes = append(listedNodes, n)
+		entries = append(entries, n.Func)
+	}
+	for _, n := range reachedSinks(listedNodes, sinks) {
+		reached = append(reached, n.Func)
+	}
+	return entries, reached, unlisted, nil
+}
+
+// reachedSinks returns the nodes in sinks that have a path in the call
+// graph from at least one of the entries (including the entry itself).
+func reachedSinks(entries, sinks []*callgraph.Node) []*callgraph.Node {
+	visited := reachable(entries, true)
+	var reached []*callgraph.Node
+	for _, s := range sinks {
+		if visited[s] {
+			reached = append(reached, s)
+		}
+	}
+	return reached
+}
+
+// reachable returns the nodes reachable from start (including start)
+// by following call edges forward, from callers to callees, or, if
+// forward is false, backward.
+func reachable(start []*callgraph.Node, forward bool) map[*callgraph.Node]bool {
+	visited := make(map[*callgraph.Node]bool)
+	stack := slices.Clone(start)
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if visited[n] {
+			continue
+		}
+		visited[n] = true
+		if forward {
+			for _, e := range n.Out {
+				stack = append(stack, e.Callee)
+			}
+		} else {
+			for _, e := range n.In {
+				stack = append(stack, e.Caller)
+			}
+		}
+	}
+	return visited
+}
+
+// writeSSA writes the SSA of pkgs to w, followed by that of the
+// functions on call paths from entries to sinks, sorted by name.
+// It is for debugging.
+func writeSSA(w io.Writer, pkgs []*ssa.Package, entries, sinks []*callgraph.Node) error {
+	for _, p := range pkgs {
+		if _, err := p.WriteTo(w); err != nil {
+			return err
+		}
+	}
+	from := reachable(entries, true)
+	to := reachable(sinks, false)
+	var fns []*ssa.Function
+	for n := range from {
+		if to[n] && n.Func != nil {
+			fns = append(fns, n.Func)
+		}
+	}
+	sort.Slice(fns, func(i, j int) bool { return fns[i].String() < fns[j].String() })
+	for _, f := range fns {
+		if _, err := f.WriteTo(w); err != nil {
+			return err
+		}
 	}
-	return vres, nil
+	return nil
 }
 
-// vulnFuncs returns functions/methods of cg deemed vulnerable by m.
+// reachesAny reports whether there is a path in the call graph
+// from n to a node in sinks (including n itself).
+func reachesAny(n *callgraph.Node, sinks map[*callgraph.Node]bool) bool {
+	visited := make(map[*callgraph.Node]bool)
+	stack := []*callgraph.Node{n}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if sinks[n] {
+			return true
+		}
+		if visited[n] {
+			continue
+		}
+		visited[n] = true
+		for _, edge := range n.Out {
+			stack = append(stack, edge.Callee)
+		}
+	}
+	return false
+}
+
+// vulnFuncs returns functions/methods of cg deemed vulnerable by m:
+// those listed in the symbols of its packages and, if derived is set,
+// those in the derived symbols.
 //
 // It mimics golang.org/x/vuln/internal/vulncheck/source.go:vulnFuncs.
-func vulnFuncs(cg *callgraph.Graph, m *report.Module) []*callgraph.Node {
+func vulnFuncs(cg *callgraph.Graph, m *report.Module, derived bool) []*callgraph.Node {
 	// Create a set of vulnerable symbols easy to query.
 	type vulnSym struct {
 		pkg string
@@ -66,6 +198,9 @@ func vulnFuncs(cg *callgraph.Graph, m *report.Module) []*callgraph.Node {
 		for _, s := range p.Symbols {
 			vulnSyms[vulnSym{p.Package, s}] = true
 		}
+		if !derived {
+			continue
+		}
 		for _, s := range p.DerivedSymbols { // for sanity
 			vulnSyms[vulnSym{p.Package, s}] = true
 		}
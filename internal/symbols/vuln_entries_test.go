@@ -0,0 +1,107 @@
+// Copyright 2023 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package symbols
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/exp/slices"
+	"golang.org/x/tools/go/callgraph"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/txtar"
+	"golang.org/x/vulndb/internal/report"
+)
+
+// TestVulnEntries checks that vulnEntries agrees with govulncheck, whose
+// results for the same module are in testdata/vulncheck.txtar. In
+// particular, VTA resolves interface calls to the types that actually
+// flow to them, so Safe, which only calls Write on a safe
+// implementation, is not an entry, though a CHA-only call graph would
+// report it. Exported methods of unexported types are also entries, as
+// they may be called through an interface.
+func TestVulnEntries(t *testing.T) {
+	ar, err := txtar.ParseFile("testdata/vulncheck.txtar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	var golden string
+	for _, f := range ar.Files {
+		if f.Name == "govulncheck.golden" {
+			golden = string(f.Data)
+			continue
+		}
+		files[f.Name] = string(f.Data)
+	}
+	dir := t.TempDir()
+	writeFiles(t, dir, files)
+
+	cfg := (&Options{Env: []string{"GOPROXY=off", "GOFLAGS=-mod=mod"}}).packagesConfig(dir)
+	pkg, err := loadPackage(cfg, "example.com/m/p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &report.Module{
+		Module: "example.com/m",
+		Packages: []*report.Package{
+			{Package: "example.com/m/p", Symbols: []string{"vuln"}},
+		},
+	}
+	entries, _, _, err := vulnEntries([]*packages.Package{pkg}, m, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range entries {
+		got = append(got, dbFuncName(f))
+	}
+	sort.Strings(got)
+	want := []string{"Direct", "Indirect", "T.M", "Unsafe", "bad.Write"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want, +got):\n%s", diff)
+	}
+
+	// Each function that a client can call is an entry if and only if
+	// govulncheck reports that calling it calls vuln.
+	for _, line := range strings.Split(strings.TrimSpace(golden), "\n") {
+		name, trace, ok := strings.Cut(line, ": ")
+		if !ok {
+			t.Fatalf("bad line in govulncheck.golden: %q", line)
+		}
+		called := trace != "not called"
+		if isEntry := slices.Contains(got, name); isEntry != called {
+			t.Errorf("%s: entry = %t, but govulncheck reports %s", name, isEntry, trace)
+		}
+	}
+}
+
+func TestReachesAny(t *testing.T) {
+	// a -> b -> vuln, c -> d, with a cycle d -> c.
+	a, b, c, d, vuln := &callgraph.Node{}, &callgraph.Node{}, &callgraph.Node{}, &callgraph.Node{}, &callgraph.Node{}
+	callgraph.AddEdge(a, nil, b)
+	callgraph.AddEdge(b, nil, vuln)
+	callgraph.AddEdge(c, nil, d)
+	callgraph.AddEdge(d, nil, c)
+	sinks := map[*callgraph.Node]bool{vuln: true}
+
+	for _, test := range []struct {
+		name string
+		n    *callgraph.Node
+		want bool
+	}{
+		{"a", a, true},
+		{"b", b, true},
+		{"vuln", vuln, true},
+		{"c", c, false},
+		{"d", d, false},
+	} {
+		if got := reachesAny(test.n, sinks); got != test.want {
+			t.Errorf("reachesAny(%s) = %t, want %t", test.name, got, test.want)
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"regexp"
 	"strings"
 
+	"golang.org/x/mod/module"
 	"golang.org/x/mod/semver"
 )
 
@@ -48,6 +49,18 @@ func TrimPrefix(v string) string {
 	return v
 }
 
+// IsPseudo reports whether v is an unprefixed pseudo-version.
+func IsPseudo(v string) bool {
+	return module.IsPseudoVersion("v" + v)
+}
+
+// IsPrerelease reports whether v is an unprefixed semantic version
+// with a prerelease suffix, such as "1.3.0-rc.1", that is not a
+// pseudo-version.
+func IsPrerelease(v string) bool {
+	return semver.Prerelease("v"+v) != "" && !IsPseudo(v)
+}
+
 var commitHashRegex = regexp.MustCompile(`^[a-f0-9]+$`)
 
 func IsCommitHash(v string) bool {